@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/rethinkdb/prometheus-exporter/config"
+	"github.com/rethinkdb/prometheus-exporter/exporter"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// checkTimeout limits the queries of the check if the scrape timeout is disabled
+const checkTimeout = 30 * time.Second
+
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Validate the config, the tls material and the database credentials",
+	Long: `Validates the config file and the flags, reads the tls material and the credentials,
+and checks that the clusters can be queried and the user can read the rethinkdb system tables of the enabled collectors.
+It exits with a non-zero code on the first failed step, for use in CI and pre-deployment hooks.
+Logs are written to stderr.`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// stdout is reserved for the results
+		var err error
+		log, err = newLogger(os.Stderr, cfg)
+		if err != nil {
+			return err
+		}
+
+		var strict config.Config
+		err = viper.UnmarshalExact(&strict, viper.DecodeHook(decodeHook()))
+		if err != nil {
+			return fmt.Errorf("config is invalid: %w", err)
+		}
+		fmt.Println("config: ok")
+
+		rconn, opts, _, err := prepare(cfg)
+		if err != nil {
+			return fmt.Errorf("credentials or tls material are invalid: %w", err)
+		}
+		defer closeConnection(rconn)
+		fmt.Println("credentials and tls material: ok")
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		timeout := cfg.Stats.ScrapeTimeout
+		if timeout <= 0 {
+			timeout = checkTimeout
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		err = exporter.Check(ctx, log, rconn, opts)
+		if err != nil {
+			return err
+		}
+		fmt.Println("rethinkdb access: ok")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"os"
+
+	"github.com/rethinkdb/prometheus-exporter/config"
+)
+
+// credentialFiles returns paths of the files with credentials of the rethinkdb connections
+func credentialFiles(cfg config.Config) []string {
+	candidates := []string{cfg.DB.PasswordFile, cfg.DB.CAFile, cfg.DB.CertificateFile, cfg.DB.KeyFile, cfg.Vault.TokenFile}
+	for _, c := range cfg.Clusters {
+		candidates = append(candidates, c.PasswordFile, c.CAFile, c.CertificateFile, c.KeyFile)
+	}
+
+	var files []string
+	for _, f := range candidates {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// credentialsWatcher detects changes of the credential files by their content,
+// so files replaced by a new symlink like kubernetes secret volumes are detected too
+type credentialsWatcher struct {
+	sums map[string][sha256.Size]byte
+}
+
+func newCredentialsWatcher(files []string) *credentialsWatcher {
+	w := &credentialsWatcher{sums: map[string][sha256.Size]byte{}}
+	w.changed(files)
+	return w
+}
+
+// changed returns true if content of any of the files differs from the last check.
+// Files which can not be read are skipped, they may be in the middle of being replaced.
+func (w *credentialsWatcher) changed(files []string) bool {
+	changed := false
+	sums := make(map[string][sha256.Size]byte, len(files))
+	for _, f := range files {
+		content, err := os.ReadFile(f)
+		if err != nil {
+			log.Warn("failed to read credentials file", "file", f, "error", err)
+			if sum, ok := w.sums[f]; ok {
+				sums[f] = sum
+			}
+			continue
+		}
+		sum := sha256.Sum256(content)
+		if last, ok := w.sums[f]; ok && last != sum {
+			log.Info("credentials file changed", "file", f)
+			changed = true
+		}
+		sums[f] = sum
+	}
+	w.sums = sums
+	return changed
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/rethinkdb/prometheus-exporter/exporter"
+	"github.com/spf13/cobra"
+)
+
+var dumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Collect the metrics once and print them in the prometheus text format",
+	Long: `Connects to the rethinkdb, collects the metrics once with the configured collectors and prints them to stdout.
+It exits with a non-zero code if any collector failed or a cluster couldn't be queried,
+which is useful for debugging permissions and connectivity and for cron-based collection.
+Logs are written to stderr.`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// stdout is reserved for the metrics
+		var err error
+		log, err = newLogger(os.Stderr, cfg)
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		rconn, opts, _, err := prepare(cfg)
+		if err != nil {
+			return err
+		}
+		defer closeConnection(rconn)
+
+		return exporter.Dump(ctx, log, rconn, opts, os.Stdout)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dumpCmd)
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package cmd
+
+// runAsService runs the exporter as a native service of the OS if it is started by the service manager.
+// It returns false when the exporter is started in a regular way.
+func runAsService() bool {
+	return false
+}
@@ -0,0 +1,227 @@
+//go:build windows
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/eventlog"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+const (
+	serviceName        = "rethinkdb-exporter"
+	serviceDisplayName = "RethinkDB Prometheus Exporter"
+	serviceDescription = "Exports RethinkDB statistics to Prometheus"
+)
+
+var serviceCmd = &cobra.Command{
+	Use:   "service",
+	Short: "Manage the exporter as a Windows service",
+}
+
+var serviceInstallCmd = &cobra.Command{
+	Use:   "install [-- flags of the exporter]",
+	Short: "Install the exporter as a Windows service started with the given flags",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		exe, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to locate executable: %w", err)
+		}
+		exe, err = filepath.Abs(exe)
+		if err != nil {
+			return fmt.Errorf("failed to locate executable: %w", err)
+		}
+
+		m, err := mgr.Connect()
+		if err != nil {
+			return fmt.Errorf("failed to connect to service manager: %w", err)
+		}
+		defer func() { _ = m.Disconnect() }()
+
+		s, err := m.CreateService(serviceName, exe, mgr.Config{
+			DisplayName: serviceDisplayName,
+			Description: serviceDescription,
+			StartType:   mgr.StartAutomatic,
+		}, args...)
+		if err != nil {
+			return fmt.Errorf("failed to create service: %w", err)
+		}
+		defer func() { _ = s.Close() }()
+
+		err = eventlog.InstallAsEventCreate(serviceName, eventlog.Error|eventlog.Warning|eventlog.Info)
+		if err != nil {
+			_ = s.Delete()
+			return fmt.Errorf("failed to install event log source: %w", err)
+		}
+		return nil
+	},
+}
+
+var serviceUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the exporter's Windows service",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		m, err := mgr.Connect()
+		if err != nil {
+			return fmt.Errorf("failed to connect to service manager: %w", err)
+		}
+		defer func() { _ = m.Disconnect() }()
+
+		s, err := m.OpenService(serviceName)
+		if err != nil {
+			return fmt.Errorf("failed to open service: %w", err)
+		}
+		defer func() { _ = s.Close() }()
+
+		err = s.Delete()
+		if err != nil {
+			return fmt.Errorf("failed to delete service: %w", err)
+		}
+		err = eventlog.Remove(serviceName)
+		if err != nil {
+			return fmt.Errorf("failed to remove event log source: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	serviceCmd.AddCommand(serviceInstallCmd, serviceUninstallCmd)
+	rootCmd.AddCommand(serviceCmd)
+}
+
+// runAsService runs the exporter as a native service of the OS if it is started by the service manager.
+// It returns false when the exporter is started in a regular way.
+func runAsService() bool {
+	isService, err := svc.IsWindowsService()
+	if err != nil {
+		log.Error("failed to detect windows service", "error", err)
+		os.Exit(1)
+	}
+	if !isService {
+		return false
+	}
+
+	el, err := eventlog.Open(serviceName)
+	if err != nil {
+		log.Error("failed to open event log", "error", err)
+		os.Exit(1)
+	}
+	defer func() { _ = el.Close() }()
+	log = slog.New(newEventLogHandler(el))
+
+	err = svc.Run(serviceName, &exporterService{})
+	if err != nil {
+		log.Error("failed to run windows service", "error", err)
+		os.Exit(1)
+	}
+	return true
+}
+
+// exporterService handles requests of the windows service manager
+type exporterService struct{}
+
+// Execute runs the exporter until the service manager stops it
+func (s *exporterService) Execute(_ []string, requests <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	const accepted = svc.AcceptStop | svc.AcceptShutdown
+
+	changes <- svc.Status{State: svc.StartPending}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- serve(ctx)
+	}()
+
+	changes <- svc.Status{State: svc.Running, Accepts: accepted}
+
+	for {
+		select {
+		case err := <-serveErr:
+			if err != nil {
+				log.Error("failed to run rethinkdb exporter", "error", err)
+				return false, 1
+			}
+			return false, 0
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				changes <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				changes <- svc.Status{State: svc.StopPending}
+				cancel()
+				err := <-serveErr
+				if err != nil {
+					log.Error("failed to run rethinkdb exporter", "error", err)
+					return false, 1
+				}
+				return false, 0
+			default:
+				log.Warn("unexpected service control request", "cmd", req.Cmd)
+			}
+		}
+	}
+}
+
+// eventLogHandler writes log records formatted by the wrapped handler to the windows event log
+type eventLogHandler struct {
+	el      *eventlog.Log
+	handler slog.Handler
+	buf     *bytes.Buffer
+	m       *sync.Mutex
+}
+
+func newEventLogHandler(el *eventlog.Log) *eventLogHandler {
+	buf := new(bytes.Buffer)
+	return &eventLogHandler{
+		el:      el,
+		handler: slog.NewTextHandler(buf, &slog.HandlerOptions{Level: logLevel}),
+		buf:     buf,
+		m:       new(sync.Mutex),
+	}
+}
+
+func (h *eventLogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *eventLogHandler) Handle(ctx context.Context, record slog.Record) error {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	h.buf.Reset()
+	err := h.handler.Handle(ctx, record)
+	if err != nil {
+		return err
+	}
+
+	const eventID = 1
+	msg := h.buf.String()
+	switch {
+	case record.Level >= slog.LevelError:
+		return h.el.Error(eventID, msg)
+	case record.Level >= slog.LevelWarn:
+		return h.el.Warning(eventID, msg)
+	default:
+		return h.el.Info(eventID, msg)
+	}
+}
+
+func (h *eventLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &eventLogHandler{el: h.el, handler: h.handler.WithAttrs(attrs), buf: h.buf, m: h.m}
+}
+
+func (h *eventLogHandler) WithGroup(name string) slog.Handler {
+	return &eventLogHandler{el: h.el, handler: h.handler.WithGroup(name), buf: h.buf, m: h.m}
+}
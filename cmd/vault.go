@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rethinkdb/prometheus-exporter/config"
+	"github.com/rethinkdb/prometheus-exporter/dbconnector"
+)
+
+const vaultTimeout = 30 * time.Second
+
+// vaultLease keeps the credentials read from the vault valid.
+// Leases of dynamic secrets are renewed until they can't be extended anymore,
+// then the config is reloaded to read new credentials.
+type vaultLease struct {
+	client *dbconnector.VaultClient
+	cfg    config.Config
+	secret *dbconnector.VaultSecret
+	timer  *time.Timer
+}
+
+// readVaultCredentials reads the rethinkdb user from the vault, it returns nil if the vault is not configured
+func readVaultCredentials(cfg config.Config) (*vaultLease, error) {
+	if cfg.Vault.Address == "" {
+		return nil, nil
+	}
+	if cfg.Vault.Path == "" {
+		return nil, fmt.Errorf("vault secret path must be specified")
+	}
+
+	token := cfg.Vault.Token
+	if cfg.Vault.TokenFile != "" {
+		var err error
+		token, err = readSecretFile(cfg.Vault.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read vault token file: %w", err)
+		}
+	}
+
+	l := &vaultLease{
+		client: dbconnector.NewVaultClient(cfg.Vault.Address, token, cfg.Vault.Namespace),
+		cfg:    cfg,
+	}
+	var err error
+	l.secret, err = l.read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials from vault: %w", err)
+	}
+	log.Info("read credentials from vault", "path", cfg.Vault.Path, "lease_duration", l.secret.LeaseDuration, "renewable", l.secret.Renewable)
+	return l, nil
+}
+
+func (l *vaultLease) read() (*dbconnector.VaultSecret, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), vaultTimeout)
+	defer cancel()
+	return l.client.ReadCredentials(ctx, l.cfg.Vault.Path, l.cfg.Vault.UsernameKey, l.cfg.Vault.PasswordKey)
+}
+
+// schedule starts the timer of renewing the lease, the returned channel is nil if the secret has no lease
+func (l *vaultLease) schedule(after time.Duration) <-chan time.Time {
+	if l == nil || after <= 0 {
+		return nil
+	}
+	l.stop()
+	l.timer = time.NewTimer(after)
+	return l.timer.C
+}
+
+// start schedules the first renewal of the lease
+func (l *vaultLease) start() <-chan time.Time {
+	if l == nil {
+		return nil
+	}
+	return l.schedule(renewalTime(l.secret.LeaseDuration))
+}
+
+func (l *vaultLease) stop() {
+	if l != nil && l.timer != nil {
+		l.timer.Stop()
+	}
+}
+
+// renew extends the lease and schedules the next renewal.
+// It returns false if the credentials have to be read again.
+func (l *vaultLease) renew() (<-chan time.Time, bool) {
+	if l.secret.LeaseID == "" {
+		// static secrets are read again in case they were rotated
+		if l.changed() {
+			return nil, false
+		}
+		return l.schedule(renewalTime(l.secret.LeaseDuration)), true
+	}
+	if !l.secret.Renewable {
+		return nil, false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), vaultTimeout)
+	defer cancel()
+	duration, err := l.client.RenewLease(ctx, l.secret.LeaseID, l.secret.LeaseDuration)
+	if err != nil {
+		log.Error("failed to renew vault lease", "error", err)
+		return nil, false
+	}
+	if duration <= l.secret.LeaseDuration/3 {
+		log.Info("vault lease reaches its maximal ttl", "lease_duration", duration)
+		return nil, false
+	}
+
+	log.Debug("renewed vault lease", "lease_duration", duration)
+	return l.schedule(renewalTime(duration)), true
+}
+
+// changed reads the secret again and reports whether the credentials differ from the current ones
+func (l *vaultLease) changed() bool {
+	if l == nil || l.secret.LeaseID != "" {
+		return false
+	}
+	secret, err := l.read()
+	if err != nil {
+		log.Error("failed to read credentials from vault", "error", err)
+		return false
+	}
+	if secret.Username != l.secret.Username || secret.Password != l.secret.Password {
+		log.Info("credentials in vault changed", "path", l.cfg.Vault.Path)
+		return true
+	}
+	return false
+}
+
+// renewalTime leaves a third of the lease to renew it or read new credentials
+func renewalTime(leaseDuration time.Duration) time.Duration {
+	return leaseDuration * 2 / 3
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/prometheus/common/version"
+	"github.com/spf13/cobra"
+)
+
+// programName is the name of the exporter in the version information
+const programName = "rethinkdb-exporter"
+
+// buildInfo is the version information printed in the JSON format
+type buildInfo struct {
+	Version   string `json:"version"`
+	Revision  string `json:"revision"`
+	Branch    string `json:"branch"`
+	BuildUser string `json:"build_user"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+}
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the version, git revision, build date and Go version",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		switch output {
+		case "text":
+			fmt.Println(version.Print(programName))
+			return nil
+		case "json":
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(buildInfo{
+				Version:   version.Version,
+				Revision:  version.GetRevision(),
+				Branch:    version.Branch,
+				BuildUser: version.BuildUser,
+				BuildDate: version.BuildDate,
+				GoVersion: version.GoVersion,
+				Platform:  version.GoOS + "/" + version.GoArch,
+			})
+		default:
+			return fmt.Errorf("unknown output format %q, expected text or json", output)
+		}
+	},
+}
+
+func init() {
+	versionCmd.Flags().StringP("output", "o", "text", "Output format: text or json")
+	rootCmd.AddCommand(versionCmd)
+
+	// --version prints the same information as the version subcommand
+	rootCmd.Version = version.Version
+	if rootCmd.Version == "" {
+		rootCmd.Version = "unknown"
+	}
+	rootCmd.SetVersionTemplate(version.Print(programName) + "\n")
+}
@@ -1,30 +1,269 @@
 package config
 
+import "time"
+
 // Config defines the exporter's parameters
 type Config struct {
 	// Web defines http-server for prometheus protocol
 	Web struct {
 		// ListenAddress is http listen endpoint
 		ListenAddress string `mapstructure:"listen_address"`
+		// SystemdSocket enables listening on the socket passed by systemd socket activation
+		SystemdSocket bool `mapstructure:"systemd_socket"`
 		// TelemetryPath is http url path for metrics
 		TelemetryPath string `mapstructure:"telemetry_path"`
+		// MetaTelemetryPath is http url path for the exporter's own metrics, served with the other metrics if empty
+		MetaTelemetryPath string `mapstructure:"meta_telemetry_path"`
+		// DisableExporterMetrics drops the Go runtime, process and promhttp metrics of the exporter
+		DisableExporterMetrics bool `mapstructure:"disable_exporter_metrics"`
+		// EnableExporterMetrics serves the Go runtime, process and promhttp metrics even if they are disabled
+		EnableExporterMetrics bool `mapstructure:"enable_exporter_metrics"`
+		// ShutdownTimeout limits time of graceful shutdown
+		ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+		// BearerToken is required from clients requesting metrics if it is not empty
+		BearerToken string `mapstructure:"bearer_token"`
+		// BearerTokenFile locates path of the file with the bearer token
+		BearerTokenFile string `mapstructure:"bearer_token_file"`
+		// TLSCertFile locates path of the certificate file of the http-server
+		TLSCertFile string `mapstructure:"tls_cert_file"`
+		// TLSKeyFile locates path of the key file to the certificate of the http-server
+		TLSKeyFile string `mapstructure:"tls_key_file"`
+		// TLSClientCAFile locates path of the CA file verifying required client certificates
+		TLSClientCAFile string `mapstructure:"tls_client_ca_file"`
+		// ConfigFile locates path of the web config file with TLS and basic auth settings of the http-server
+		ConfigFile string `mapstructure:"config_file"`
+		// EnablePprof serves profiles of the exporter under /debug/pprof
+		EnablePprof bool `mapstructure:"enable_pprof"`
+		// PprofAddress is listen endpoint of a separate http-server for the profiles
+		PprofAddress string `mapstructure:"pprof_address"`
+		// AdminAddress is listen endpoint of a separate http-server for the health, readiness and admin endpoints
+		AdminAddress string `mapstructure:"admin_address"`
+		// AccessLog logs every request served by the http-server
+		AccessLog bool `mapstructure:"access_log"`
+		// MaxRequests limits concurrent requests for the metrics, zero means no limit
+		MaxRequests int `mapstructure:"max_requests"`
+		// ReadTimeout limits reading of a whole request, zero means no limit
+		ReadTimeout time.Duration `mapstructure:"read_timeout"`
+		// ReadHeaderTimeout limits reading of the request headers
+		ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout"`
+		// WriteTimeout limits writing of the response, zero means no limit
+		WriteTimeout time.Duration `mapstructure:"write_timeout"`
+		// IdleTimeout limits waiting for the next request on a keep-alive connection, the read timeout is used if zero
+		IdleTimeout time.Duration `mapstructure:"idle_timeout"`
+		// MaxHeaderBytes limits size of the request headers
+		MaxHeaderBytes int `mapstructure:"max_header_bytes"`
+		// ReadyGracePeriod is how long scrapes may fail before the exporter is reported as not ready
+		ReadyGracePeriod time.Duration `mapstructure:"ready_grace_period"`
+		// ExternalURL is the url the exporter is reachable at through a reverse proxy
+		ExternalURL string `mapstructure:"external_url"`
+		// LandingPage customizes the page served on the / path
+		LandingPage struct {
+			// Disabled serves 404 instead of the page
+			Disabled bool `mapstructure:"disabled"`
+			// Title of the page
+			Title string `mapstructure:"title"`
+			// Description is shown under the title
+			Description string `mapstructure:"description"`
+			// Links are shown besides the links of the exporter's paths
+			Links []LandingPageLink `mapstructure:"links"`
+		} `mapstructure:"landing_page"`
 	} `mapstructure:"web"`
 
 	// Stats defines collecting stats parameters
 	Stats struct {
 		// TableDocsEstimates tells the exporter to get table rows count estimates
 		TableDocsEstimates bool `mapstructure:"table_docs_estimates"`
+		// ShardEstimates tells the exporter to export the docs count estimates of every shard of the tables
+		ShardEstimates bool `mapstructure:"shard_docs_estimates"`
+		// TableEstimatesConcurrency limits number of concurrent queries of the table rows count estimates
+		TableEstimatesConcurrency int `mapstructure:"table_estimates_concurrency"`
+		// TableEstimatesTimeout limits duration of every query of the table rows count estimates
+		TableEstimatesTimeout time.Duration `mapstructure:"table_estimates_timeout"`
+		// ExactCountTables are tables given as db.table whose rows are counted exactly
+		ExactCountTables []string `mapstructure:"exact_count_tables"`
+		// ExactCountInterval is the interval of counting the rows exactly in the background
+		ExactCountInterval time.Duration `mapstructure:"exact_count_interval"`
+		// DBFilter limits per-table and per-replica metrics to the databases matching the regular expression
+		DBFilter string `mapstructure:"db_filter"`
+		// DBExclude drops per-table and per-replica metrics of the databases matching the regular expression
+		DBExclude string `mapstructure:"db_exclude"`
+		// TableFilter limits per-table and per-replica metrics to the tables matching the regular expression
+		TableFilter string `mapstructure:"table_filter"`
+		// TableExclude drops per-table and per-replica metrics of the tables matching the regular expression
+		TableExclude string `mapstructure:"table_exclude"`
+		// ServerSideFilters applies the filters in the stats queries as well
+		ServerSideFilters bool `mapstructure:"server_side_filters"`
+		// ServerFilter limits per-server and per-replica metrics to the servers matching the regular expression
+		ServerFilter string `mapstructure:"server_filter"`
+		// ServerExclude drops per-server and per-replica metrics of the servers matching the regular expression
+		ServerExclude string `mapstructure:"server_exclude"`
+		// ScrapeTimeout limits duration of collecting stats on every scrape
+		ScrapeTimeout time.Duration `mapstructure:"scrape_timeout"`
+		// OutdatedReads runs the queries with the outdated read mode
+		OutdatedReads bool `mapstructure:"outdated_reads"`
+		// QueryRetries is how many times queries failed with connection errors are retried during a scrape
+		QueryRetries int `mapstructure:"query_retries"`
+		// QueryRetryBackoff is the wait before the first retry, doubled for every next one
+		QueryRetryBackoff time.Duration `mapstructure:"query_retry_backoff"`
+		// BreakerThreshold is number of consecutive failed scrapes after which the cluster is not queried for the cooldown
+		BreakerThreshold int `mapstructure:"breaker_threshold"`
+		// BreakerCooldown is time the cluster is not queried after the failed scrapes
+		BreakerCooldown time.Duration `mapstructure:"breaker_cooldown"`
+		// Stream keeps the stats and table_status system tables up to date by changefeeds instead of querying them on every scrape
+		Stream bool `mapstructure:"stream"`
+		// CollectInterval enables collecting stats in the background, scrapes are served from the cache
+		CollectInterval time.Duration `mapstructure:"collect_interval"`
+		// MaxStaleness limits age of the cached stats served in the background collection mode
+		MaxStaleness time.Duration `mapstructure:"max_staleness"`
+		// MinScrapeInterval serves the last stats to the scrapes arriving sooner than the interval after the last one
+		MinScrapeInterval time.Duration `mapstructure:"min_scrape_interval"`
+		// RejectFrequentScrapes rejects the scrapes arriving sooner than the min scrape interval with 429
+		RejectFrequentScrapes bool `mapstructure:"reject_frequent_scrapes"`
 	} `mapstructure:"stats"`
 
+	// Collectors enables or disables collectors by name
+	Collectors map[string]bool `mapstructure:"collectors"`
+
+	// Metrics defines how collected stats are exported
+	Metrics struct {
+		// ServerIDLabel adds the server_id label with the uuid of the server to the per-server metrics
+		ServerIDLabel bool `mapstructure:"server_id_label"`
+		// ServerRoleLabel adds the role label, either data or proxy, to the server stats
+		ServerRoleLabel bool `mapstructure:"server_role_label"`
+		// TableIDLabel adds the table_id label with the uuid of the table to the per-table metrics
+		TableIDLabel bool `mapstructure:"table_id_label"`
+		// LabelRenames maps default label names to the custom ones
+		LabelRenames map[string]string `mapstructure:"label_renames"`
+		// Namespace prefixes names of the metrics instead of rethinkdb
+		Namespace string `mapstructure:"namespace"`
+		// Labels are static labels added to all exported metrics
+		Labels map[string]string `mapstructure:"labels"`
+		// ScrapeDurationBuckets are upper bounds of the scrape duration histogram buckets
+		ScrapeDurationBuckets []float64 `mapstructure:"scrape_duration_buckets"`
+		// LegacyScrapeLatency enables the scrape_latency gauge for compatibility with old dashboards
+		LegacyScrapeLatency bool `mapstructure:"legacy_scrape_latency"`
+		// LegacyNames exports the stats metrics under the names of the oliver006/rethinkdb_exporter, either additional or exclusive
+		LegacyNames string `mapstructure:"legacy_names"`
+		// NativeHistograms enables native histograms of the exporter's latency metrics
+		NativeHistograms bool `mapstructure:"native_histograms"`
+		// EnableOpenMetrics serves the OpenMetrics format to the scrapers requesting it
+		EnableOpenMetrics bool `mapstructure:"enable_openmetrics"`
+		// OpenMetricsCreatedSamples adds the _created samples to the OpenMetrics output
+		OpenMetricsCreatedSamples bool `mapstructure:"openmetrics_created_samples"`
+		// ExpositionFormat forces the format of the served metrics regardless of the Accept header
+		ExpositionFormat string `mapstructure:"exposition_format"`
+		// ClusterLabel is name of the label distinguishing metrics of the clusters if other clusters are configured
+		ClusterLabel string `mapstructure:"cluster_label"`
+	} `mapstructure:"metrics"`
+
+	// OTLP defines pushing the metrics to an OpenTelemetry collector
+	OTLP struct {
+		// Endpoint is the url of the collector, the metrics are not pushed if it is empty
+		Endpoint string `mapstructure:"endpoint"`
+		// Protocol is http/protobuf or grpc
+		Protocol string `mapstructure:"protocol"`
+		// Interval of pushing the metrics
+		Interval time.Duration `mapstructure:"interval"`
+		// Headers are added to the push requests, e.g. for authentication
+		Headers map[string]string `mapstructure:"headers"`
+	} `mapstructure:"otlp"`
+
+	// Push defines pushing the metrics to a Pushgateway
+	Push struct {
+		// GatewayURL is url of the Pushgateway, the metrics are not pushed if it is empty
+		GatewayURL string `mapstructure:"gateway_url"`
+		// Job is the job label of the pushed metrics
+		Job string `mapstructure:"job"`
+		// Grouping are the grouping labels of the pushed metrics besides the job
+		Grouping map[string]string `mapstructure:"grouping"`
+		// Interval of pushing the metrics
+		Interval time.Duration `mapstructure:"interval"`
+	} `mapstructure:"push"`
+
+	// Graphite defines pushing the metrics to a Carbon endpoint of Graphite
+	Graphite struct {
+		// Address of the Carbon plaintext endpoint given as host:port, the metrics are not pushed if it is empty
+		Address string `mapstructure:"address"`
+		// Prefix is prepended to the names of the pushed metrics
+		Prefix string `mapstructure:"prefix"`
+		// UseTags pushes the labels as Graphite tags instead of appending them to the names
+		UseTags bool `mapstructure:"use_tags"`
+		// Interval of pushing the metrics
+		Interval time.Duration `mapstructure:"interval"`
+	} `mapstructure:"graphite"`
+
+	// StatsD defines emitting the metrics as StatsD or DogStatsD gauges
+	StatsD struct {
+		// Address of the statsd server or the datadog agent given as host:port, the metrics are not emitted if it is empty
+		Address string `mapstructure:"address"`
+		// Flavor is either statsd appending the labels to the names, or dogstatsd sending them as tags
+		Flavor string `mapstructure:"flavor"`
+		// Prefix is prepended to the names of the emitted metrics
+		Prefix string `mapstructure:"prefix"`
+		// Interval of emitting the metrics
+		Interval time.Duration `mapstructure:"interval"`
+	} `mapstructure:"statsd"`
+
+	// Once defines the mode collecting the metrics exactly once, e.g. for smoke tests
+	Once struct {
+		// Enabled collects the metrics once and exits with non-zero code if the collection failed
+		Enabled bool `mapstructure:"enabled"`
+		// ServeDuration is how long the metrics of the single collection are served before exiting
+		ServeDuration time.Duration `mapstructure:"serve_duration"`
+	} `mapstructure:"once"`
+
+	// Output defines writing the metrics instead of serving them
+	Output struct {
+		// TextfileDir is the directory read by the textfile collector of the node_exporter,
+		// the metrics are written there instead of being served if it is not empty
+		TextfileDir string `mapstructure:"textfile_dir"`
+		// TextfileInterval is interval of writing the metrics to the textfile directory
+		TextfileInterval time.Duration `mapstructure:"textfile_interval"`
+	} `mapstructure:"output"`
+
+	// Mock defines serving synthetic metrics without a rethinkdb, e.g. for developing dashboards
+	Mock struct {
+		// Enabled serves the metrics of a generated demo cluster instead of connecting to the rethinkdb
+		Enabled bool `mapstructure:"enabled"`
+		// FixturesFile is JSON file of the documents of the system tables by their name,
+		// they replace the generated ones
+		FixturesFile string `mapstructure:"fixtures_file"`
+	} `mapstructure:"mock"`
+
 	// DB defines rethinkdb-connection parameters
 	DB struct {
 		// RethinkdbAddresses list of endpoints of the rethinkdb nodes to connect
 		RethinkdbAddresses []string `mapstructure:"rethinkdb_addresses"`
+		// ClusterName is value of the cluster label of the metrics if other clusters are configured
+		ClusterName string `mapstructure:"cluster_name"`
+		// SRVRecord is DNS SRV record to discover the addresses of the nodes instead of the static list
+		SRVRecord string `mapstructure:"srv_record"`
+		// DNSName resolves to the addresses of the nodes instead of the static list, given as host:port
+		DNSName string `mapstructure:"dns_name"`
+		// AddressFile is JSON or YAML file listing the addresses of the nodes instead of the static list
+		AddressFile string `mapstructure:"address_file"`
+		// Consul discovers the addresses of the nodes by the consul service instead of the static list
+		Consul struct {
+			// Service is name of the consul service of the nodes
+			Service string `mapstructure:"service"`
+			// Address of the consul agent
+			Address string `mapstructure:"address"`
+			// Datacenter of the service, the datacenter of the agent if empty
+			Datacenter string `mapstructure:"datacenter"`
+			// Tags the service instances must have
+			Tags []string `mapstructure:"tags"`
+			// Token is the ACL token to query the consul
+			Token string `mapstructure:"token"`
+		} `mapstructure:"consul"`
+		// DiscoveryInterval is interval of discovering the addresses again to reconnect when they change
+		DiscoveryInterval time.Duration `mapstructure:"discovery_interval"`
 
 		// Username to auth in the rethinkdb
 		Username string `mapstructure:"username"`
 		// Password to auth in the rethinkdb
 		Password string `mapstructure:"password"`
+		// PasswordFile locates path of the file with the password, it overrides the password
+		PasswordFile string `mapstructure:"password_file"`
 
 		// EnableTLS enables encryption on the connection
 		EnableTLS bool `mapstructure:"enable_tls"`
@@ -34,14 +273,136 @@ type Config struct {
 		CertificateFile string `mapstructure:"certificate_file"`
 		// KeyFile locates path of the key file to the client certificate
 		KeyFile string `mapstructure:"key_file"`
+		// TLSMinVersion is minimal TLS version of the connection like TLS12
+		TLSMinVersion string `mapstructure:"tls_min_version"`
+		// TLSServerName is the name the server certificate is verified against instead of the host of the address
+		TLSServerName string `mapstructure:"tls_server_name"`
+		// TLSSkipVerify disables verification of the server certificate, it is insecure
+		TLSSkipVerify bool `mapstructure:"tls_skip_verify"`
+		// TLSCipherSuites limits cipher suites of the connection up to TLS 1.2 by their names
+		TLSCipherSuites []string `mapstructure:"tls_cipher_suites"`
+
+		// ProxyURL is url of the SOCKS5 or HTTP CONNECT proxy to connect through
+		ProxyURL string `mapstructure:"proxy_url"`
+		// SSHHost is address of the ssh jump host to tunnel the connection through
+		SSHHost string `mapstructure:"ssh_host"`
+		// SSHUser is user of the ssh jump host
+		SSHUser string `mapstructure:"ssh_user"`
+		// SSHKeyFile locates path of the private key to auth on the jump host, the ssh agent is used if empty
+		SSHKeyFile string `mapstructure:"ssh_key_file"`
+		// SSHKnownHostsFile locates path of the known hosts file verifying the jump host
+		SSHKnownHostsFile string `mapstructure:"ssh_known_hosts_file"`
 
 		// ConnectionPoolSize defines size of the connection pool to the rethinkdb
 		ConnectionPoolSize int `mapstructure:"connection_pool_size"`
+		// InitialPoolSize is number of connections opened on start, the others are opened on demand
+		InitialPoolSize int `mapstructure:"initial_pool_size"`
+		// ConnectTimeout limits establishing a connection to the rethinkdb
+		ConnectTimeout time.Duration `mapstructure:"connect_timeout"`
+		// ReadTimeout limits waiting for a response of the rethinkdb
+		ReadTimeout time.Duration `mapstructure:"read_timeout"`
+		// WriteTimeout limits sending a query to the rethinkdb
+		WriteTimeout time.Duration `mapstructure:"write_timeout"`
+		// KeepAlivePeriod is period of tcp keep alive probes of the connections
+		KeepAlivePeriod time.Duration `mapstructure:"keepalive_period"`
+		// IdleTimeout reconnects the session before a query if it was idle for longer
+		IdleTimeout time.Duration `mapstructure:"idle_timeout"`
+		// CredentialsCheckInterval is interval of checking the password and tls files for changes
+		CredentialsCheckInterval time.Duration `mapstructure:"credentials_check_interval"`
 	} `mapstructure:"db"`
 
+	// Vault defines reading the username and the password of the rethinkdb user from HashiCorp Vault
+	Vault struct {
+		// Address of the vault, the vault is not used if it is empty
+		Address string `mapstructure:"address"`
+		// Token to auth in the vault
+		Token string `mapstructure:"token"`
+		// TokenFile locates path of the file with the token, it overrides the token
+		TokenFile string `mapstructure:"token_file"`
+		// Namespace of the vault enterprise
+		Namespace string `mapstructure:"namespace"`
+		// Path of the kv secret or the database secrets engine credentials
+		Path string `mapstructure:"path"`
+		// UsernameKey is field of the secret with the username
+		UsernameKey string `mapstructure:"username_key"`
+		// PasswordKey is field of the secret with the password
+		PasswordKey string `mapstructure:"password_key"`
+	} `mapstructure:"vault"`
+
+	// Clusters defines connection parameters of other clusters scraped together with the main one by cluster name
+	Clusters map[string]Cluster `mapstructure:"clusters"`
+
+	// Modules defines connection parameters of the targets probed on the /probe path by module name
+	Modules map[string]ProbeModule `mapstructure:"modules"`
+
 	// Log defines exporter's logging
 	Log struct {
-		// Debug enables more logs for debugging
+		// Level is the minimal level of the logs, either debug, info, warn or error
+		Level string `mapstructure:"level"`
+		// Debug sets the debug level whatever the level is, it is kept for compatibility
 		Debug bool `mapstructure:"debug"`
+		// Format of the logs, either text or json
+		Format string `mapstructure:"format"`
+		// JSONOutput writes the logs as JSON whatever the format is, it is kept for compatibility
+		JSONOutput bool `mapstructure:"json_output"`
+		// File is path of the file the logs are written to instead of stdout or stderr
+		File string `mapstructure:"file"`
+		// MaxSizeMB is size of the log file in megabytes it is rotated at
+		MaxSizeMB int `mapstructure:"max_size_mb"`
+		// MaxAge is how long the rotated log files are kept, rounded up to days, they are not removed by age if zero
+		MaxAge time.Duration `mapstructure:"max_age"`
+		// MaxBackups is number of the rotated log files kept, all of them are kept if zero
+		MaxBackups int `mapstructure:"max_backups"`
+		// Compress compresses the rotated log files with gzip
+		Compress bool `mapstructure:"compress"`
 	} `mapstructure:"log"`
 }
+
+// LandingPageLink defines a link shown on the landing page
+type LandingPageLink struct {
+	// Address the link points to
+	Address string `mapstructure:"address"`
+	// Text of the link
+	Text string `mapstructure:"text"`
+}
+
+// Cluster defines connection parameters of a rethinkdb cluster scraped besides the main one
+type Cluster struct {
+	// RethinkdbAddresses list of endpoints of the rethinkdb nodes to connect
+	RethinkdbAddresses []string `mapstructure:"rethinkdb_addresses"`
+
+	// Username to auth in the rethinkdb
+	Username string `mapstructure:"username"`
+	// Password to auth in the rethinkdb
+	Password string `mapstructure:"password"`
+	// PasswordFile locates path of the file with the password, it overrides the password
+	PasswordFile string `mapstructure:"password_file"`
+
+	// EnableTLS enables encryption on the connection
+	EnableTLS bool `mapstructure:"enable_tls"`
+	// CAFile locates path of the CA file
+	CAFile string `mapstructure:"ca_file"`
+	// CertificateFile locates path of the client certificate file
+	CertificateFile string `mapstructure:"certificate_file"`
+	// KeyFile locates path of the key file to the client certificate
+	KeyFile string `mapstructure:"key_file"`
+}
+
+// ProbeModule defines connection parameters of the rethinkdb targets probed with the module
+type ProbeModule struct {
+	// Username to auth in the rethinkdb
+	Username string `mapstructure:"username"`
+	// Password to auth in the rethinkdb
+	Password string `mapstructure:"password"`
+	// TargetFilter is a regular expression the probed addresses have to match, all of them are allowed if it is empty
+	TargetFilter string `mapstructure:"target_filter"`
+
+	// EnableTLS enables encryption on the connection
+	EnableTLS bool `mapstructure:"enable_tls"`
+	// CAFile locates path of the CA file
+	CAFile string `mapstructure:"ca_file"`
+	// CertificateFile locates path of the client certificate file
+	CertificateFile string `mapstructure:"certificate_file"`
+	// KeyFile locates path of the key file to the client certificate
+	KeyFile string `mapstructure:"key_file"`
+}
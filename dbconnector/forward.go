@@ -0,0 +1,141 @@
+package dbconnector
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+)
+
+// DialFunc opens a connection to the rethinkdb node at the address
+type DialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+const forwardDialTimeout = 30 * time.Second
+
+// Forwarder listens on local ports and forwards the connections to the rethinkdb nodes through the dial function,
+// as the driver can't use a custom dialer. The connections to the nodes are encrypted by the forwarder if tlsConfig is set.
+type Forwarder struct {
+	log       *slog.Logger
+	dial      DialFunc
+	tlsConfig *tls.Config
+
+	listeners []net.Listener
+	local     []string
+	wg        sync.WaitGroup
+}
+
+// NewForwarder starts forwarding local ports to each of the addresses
+func NewForwarder(log *slog.Logger, addresses []string, dial DialFunc, tlsConfig *tls.Config) (*Forwarder, error) {
+	f := &Forwarder{
+		log:       log,
+		dial:      dial,
+		tlsConfig: tlsConfig,
+	}
+	for _, address := range addresses {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("failed to listen for forwarding to %s: %w", address, err)
+		}
+		f.listeners = append(f.listeners, l)
+		f.local = append(f.local, l.Addr().String())
+
+		f.wg.Add(1)
+		go func() {
+			defer f.wg.Done()
+			f.serve(l, address)
+		}()
+	}
+	return f, nil
+}
+
+// Addresses returns the local addresses forwarded to the nodes in the order of the nodes
+func (f *Forwarder) Addresses() []string {
+	return f.local
+}
+
+// Close stops forwarding, established connections are closed by the driver
+func (f *Forwarder) Close() error {
+	var errs []error
+	for _, l := range f.listeners {
+		errs = append(errs, l.Close())
+	}
+	f.wg.Wait()
+	return errors.Join(errs...)
+}
+
+func (f *Forwarder) serve(l net.Listener, address string) {
+	for {
+		local, err := l.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				f.log.Error("failed to accept forwarded connection", "address", address, "error", err)
+			}
+			return
+		}
+		go f.forward(local, address)
+	}
+}
+
+func (f *Forwarder) forward(local net.Conn, address string) {
+	defer func() {
+		_ = local.Close()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), forwardDialTimeout)
+	remote, err := f.dial(ctx, "tcp", address)
+	cancel()
+	if err != nil {
+		f.log.Error("failed to connect to rethinkdb", "address", address, "error", err)
+		return
+	}
+	if f.tlsConfig != nil {
+		remote, err = f.handshake(remote, address)
+		if err != nil {
+			f.log.Error("failed tls handshake with rethinkdb", "address", address, "error", err)
+			return
+		}
+	}
+	defer func() {
+		_ = remote.Close()
+	}()
+
+	done := make(chan struct{}, 2)
+	pipe := func(dst, src net.Conn) {
+		_, _ = io.Copy(dst, src)
+		// unblocks the copy in the other direction
+		_ = dst.Close()
+		_ = src.Close()
+		done <- struct{}{}
+	}
+	go pipe(remote, local)
+	go pipe(local, remote)
+	<-done
+	<-done
+}
+
+func (f *Forwarder) handshake(conn net.Conn, address string) (net.Conn, error) {
+	config := f.tlsConfig.Clone()
+	if config.ServerName == "" {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			host = address
+		}
+		config.ServerName = host
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), forwardDialTimeout)
+	defer cancel()
+	tlsConn := tls.Client(conn, config)
+	err := tlsConn.HandshakeContext(ctx)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
@@ -4,12 +4,35 @@ import (
 	"context"
 	"crypto/tls"
 	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	r "gopkg.in/rethinkdb/rethinkdb-go.v6"
 )
 
+// SessionOptions tunes the connections of the session, zero values leave the driver defaults
+type SessionOptions struct {
+	// PoolSize is maximal number of connections held in the pool
+	PoolSize int
+	// InitialPoolSize is number of connections opened when connecting, the others are opened on demand
+	InitialPoolSize int
+	// ConnectTimeout limits establishing a connection
+	ConnectTimeout time.Duration
+	// ReadTimeout limits waiting for a response to a query
+	ReadTimeout time.Duration
+	// WriteTimeout limits sending a query
+	WriteTimeout time.Duration
+	// KeepAlivePeriod is period of tcp keep alive probes of the connections
+	KeepAlivePeriod time.Duration
+	// IdleTimeout reconnects the session before a query if it was not used for longer,
+	// as idle connections may be dropped silently by stateful firewalls
+	IdleTimeout time.Duration
+}
+
 // ConnectRethinkDB establishes lazy rethinkdb connection
 // It will make attempt to connect with first call and reconnect after every error
 func ConnectRethinkDB(
@@ -17,19 +40,25 @@ func ConnectRethinkDB(
 	addresses []string,
 	username, password string,
 	tlsConfig *tls.Config,
-	poolSize int,
+	sessionOpts SessionOptions,
 ) *LazyRethinkSession {
 	const systemDatabase = "rethinkdb"
 
 	return &LazyRethinkSession{
-		log: log,
+		log:         log,
+		idleTimeout: sessionOpts.IdleTimeout,
 		opts: r.ConnectOpts{
-			Addresses: addresses,
-			Database:  systemDatabase,
-			Username:  username,
-			Password:  password,
-			TLSConfig: tlsConfig,
-			MaxOpen:   poolSize,
+			Addresses:       addresses,
+			Database:        systemDatabase,
+			Username:        username,
+			Password:        password,
+			TLSConfig:       tlsConfig,
+			MaxOpen:         sessionOpts.PoolSize,
+			InitialCap:      sessionOpts.InitialPoolSize,
+			Timeout:         sessionOpts.ConnectTimeout,
+			ReadTimeout:     sessionOpts.ReadTimeout,
+			WriteTimeout:    sessionOpts.WriteTimeout,
+			KeepAlivePeriod: sessionOpts.KeepAlivePeriod,
 		},
 	}
 }
@@ -40,17 +69,108 @@ func ConnectRethinkDB(
 type LazyRethinkSession struct {
 	*r.Session
 
-	log  *slog.Logger
-	opts r.ConnectOpts
-	m    sync.Mutex
+	log     *slog.Logger
+	opts    r.ConnectOpts
+	m       sync.Mutex
+	closers []io.Closer
+
+	idleTimeout time.Duration
+	lastUsed    time.Time
+
+	backoff     time.Duration
+	nextAttempt time.Time
+	lastErr     error
+	closed      bool
+	done        chan struct{}
+
+	connectionErrors atomic.Uint64
+}
+
+// PoolStats describes the connection pool of the session
+type PoolStats struct {
+	// MaxOpen is maximal number of connections to each node
+	MaxOpen int
+	// Connected tells if the session is connected to the cluster
+	Connected bool
+	// ConnectionErrors is total number of failed connection attempts and connections closed during queries
+	ConnectionErrors uint64
+}
+
+// PoolStats returns stats of the connection pool without connecting
+func (l *LazyRethinkSession) PoolStats() PoolStats {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	return PoolStats{
+		// the driver opens one connection if it is not set
+		MaxOpen:          max(l.opts.MaxOpen, 1),
+		Connected:        l.Session != nil && l.Session.IsConnected(),
+		ConnectionErrors: l.connectionErrors.Load(),
+	}
+}
+
+const (
+	minReconnectBackoff = time.Second
+	maxReconnectBackoff = time.Minute
+)
+
+// ErrReconnectBackoff is returned instead of connecting while waiting for the next attempt after a failed one
+var ErrReconnectBackoff = errors.New("waiting to reconnect to rethinkdb")
+
+// CloseWith registers resources of the connection closed together with the session
+func (l *LazyRethinkSession) CloseWith(c io.Closer) {
+	l.closers = append(l.closers, c)
+}
+
+// ConnectInBackground connects to the rethinkdb in the background, retrying with backoff until it succeeds,
+// so the connection is ready for the first scrape even if the rethinkdb is started after the exporter
+func (l *LazyRethinkSession) ConnectInBackground() {
+	l.m.Lock()
+	l.done = make(chan struct{})
+	l.m.Unlock()
+
+	go func() {
+		for {
+			err := l.connect()
+			if err == nil {
+				l.log.Info("connected to rethinkdb")
+				return
+			}
+			l.m.Lock()
+			wait := time.Until(l.nextAttempt)
+			l.m.Unlock()
+			if !errors.Is(err, ErrReconnectBackoff) {
+				l.log.Error("failed to connect to rethinkdb", "retry_in", wait, "error", err)
+			}
+
+			select {
+			case <-l.done:
+				return
+			case <-time.After(wait):
+			}
+		}
+	}()
 }
 
 // Close closes connections
 func (l *LazyRethinkSession) Close() error {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	l.closed = true
+	if l.done != nil {
+		close(l.done)
+		l.done = nil
+	}
+
+	var errs []error
 	if l.Session != nil {
-		return l.Session.Close()
+		errs = append(errs, l.Session.Close())
 	}
-	return nil
+	for _, c := range l.closers {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
 }
 
 // IsConnected returns true if session has a valid connection.
@@ -83,8 +203,14 @@ func (l *LazyRethinkSession) Query(ctx context.Context, q r.Query) (*r.Cursor, e
 		}
 	}
 
+	err := l.refreshIdle()
+	if err != nil {
+		return nil, err
+	}
+
 	cur, err := l.Session.Query(ctx, q)
 	if errors.Is(err, r.ErrConnectionClosed) {
+		l.connectionErrors.Add(1)
 		err = l.Reconnect()
 		if err != nil {
 			return nil, err
@@ -103,8 +229,14 @@ func (l *LazyRethinkSession) Exec(ctx context.Context, q r.Query) error {
 		}
 	}
 
-	err := l.Session.Exec(ctx, q)
+	err := l.refreshIdle()
+	if err != nil {
+		return err
+	}
+
+	err = l.Session.Exec(ctx, q)
 	if errors.Is(err, r.ErrConnectionClosed) {
+		l.connectionErrors.Add(1)
 		err = l.Reconnect()
 		if err != nil {
 			return err
@@ -114,17 +246,52 @@ func (l *LazyRethinkSession) Exec(ctx context.Context, q r.Query) error {
 	return err
 }
 
+// connect creates the session unless it exists.
+// After failed attempts it waits with exponential backoff, so scrapes fail fast while the rethinkdb is down.
 func (l *LazyRethinkSession) connect() error {
 	l.m.Lock()
 	defer l.m.Unlock()
 
-	var err error
-	if l.Session == nil {
-		l.Session, err = r.Connect(l.opts)
-		if err != nil {
-			// to connect at next attempt
-			l.Session = nil
-		}
+	if l.Session != nil {
+		return nil
 	}
-	return err
+	if l.closed {
+		return r.ErrConnectionClosed
+	}
+	if time.Now().Before(l.nextAttempt) {
+		return fmt.Errorf("%w in %s: %w", ErrReconnectBackoff, time.Until(l.nextAttempt).Round(time.Second), l.lastErr)
+	}
+
+	session, err := r.Connect(l.opts)
+	if err != nil {
+		l.connectionErrors.Add(1)
+		l.backoff = min(max(2*l.backoff, minReconnectBackoff), maxReconnectBackoff)
+		l.nextAttempt = time.Now().Add(l.backoff)
+		l.lastErr = err
+		return err
+	}
+	l.Session = session
+	l.backoff = 0
+	l.nextAttempt = time.Time{}
+	l.lastErr = nil
+	return nil
+}
+
+// refreshIdle reconnects the session if it was not used for longer than the idle timeout
+func (l *LazyRethinkSession) refreshIdle() error {
+	if l.idleTimeout <= 0 {
+		return nil
+	}
+
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	last := l.lastUsed
+	l.lastUsed = time.Now()
+	idle := l.lastUsed.Sub(last)
+	if last.IsZero() || idle <= l.idleTimeout {
+		return nil
+	}
+	l.log.Debug("reconnecting idle rethinkdb session", "idle", idle)
+	return l.Reconnect()
 }
@@ -0,0 +1,126 @@
+package dbconnector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SSHTunnel dials the rethinkdb nodes through the ssh jump host.
+// The ssh connection is established with the first dial and again after it breaks.
+type SSHTunnel struct {
+	address string
+	config  *ssh.ClientConfig
+	agent   net.Conn
+
+	m      sync.Mutex
+	client *ssh.Client
+}
+
+// NewSSHTunnel creates tunnel through the jump host at the address.
+// The user is authenticated with the key file if it is given, otherwise with the ssh agent of SSH_AUTH_SOCK.
+// Key of the host is verified with the known hosts file.
+func NewSSHTunnel(address, user, keyFile, knownHostsFile string) (*SSHTunnel, error) {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, "22")
+	}
+
+	hostKeyCallback, err := knownhosts.New(knownHostsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ssh known hosts: %w", err)
+	}
+
+	t := &SSHTunnel{address: address}
+	var auth ssh.AuthMethod
+	if keyFile != "" {
+		key, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ssh key: %w", err)
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ssh key: %w", err)
+		}
+		auth = ssh.PublicKeys(signer)
+	} else {
+		socket := os.Getenv("SSH_AUTH_SOCK")
+		if socket == "" {
+			return nil, errors.New("ssh key file must be specified if there is no ssh agent")
+		}
+		t.agent, err = net.Dial("unix", socket)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to ssh agent: %w", err)
+		}
+		auth = ssh.PublicKeysCallback(agent.NewClient(t.agent).Signers)
+	}
+
+	t.config = &ssh.ClientConfig{
+		User:            user,
+		Auth:            []ssh.AuthMethod{auth},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         forwardDialTimeout,
+	}
+	return t, nil
+}
+
+// Dial opens connection to the address through the jump host
+func (t *SSHTunnel) Dial(ctx context.Context, network, address string) (net.Conn, error) {
+	client, err := t.connect()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := client.DialContext(ctx, network, address)
+	if err != nil {
+		// the ssh connection may be broken, it is established again on the next dial
+		t.reset(client)
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (t *SSHTunnel) connect() (*ssh.Client, error) {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	if t.client == nil {
+		client, err := ssh.Dial("tcp", t.address, t.config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to ssh host %s: %w", t.address, err)
+		}
+		t.client = client
+	}
+	return t.client, nil
+}
+
+func (t *SSHTunnel) reset(client *ssh.Client) {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	if t.client == client {
+		_ = t.client.Close()
+		t.client = nil
+	}
+}
+
+// Close closes the ssh connection
+func (t *SSHTunnel) Close() error {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	var errs []error
+	if t.client != nil {
+		errs = append(errs, t.client.Close())
+		t.client = nil
+	}
+	if t.agent != nil {
+		errs = append(errs, t.agent.Close())
+	}
+	return errors.Join(errs...)
+}
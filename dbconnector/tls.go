@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 )
 
 // PrepareTLSConfig creates tls.Config with certificate files
@@ -41,3 +42,38 @@ func PrepareTLSConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
 
 	return config, nil
 }
+
+var tlsVersions = map[string]uint16{
+	"TLS10": tls.VersionTLS10,
+	"TLS11": tls.VersionTLS11,
+	"TLS12": tls.VersionTLS12,
+	"TLS13": tls.VersionTLS13,
+}
+
+// ParseTLSVersion returns id of the tls version by its name like TLS12
+func ParseTLSVersion(name string) (uint16, error) {
+	v, ok := tlsVersions[strings.ToUpper(name)]
+	if !ok {
+		return 0, fmt.Errorf("unknown TLS version %q, must be one of TLS10, TLS11, TLS12, TLS13", name)
+	}
+	return v, nil
+}
+
+// ParseCipherSuites returns ids of the cipher suites by their names like TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256.
+// Only suites without known security issues are accepted.
+func ParseCipherSuites(names []string) ([]uint16, error) {
+	suites := make(map[string]uint16)
+	for _, s := range tls.CipherSuites() {
+		suites[s.Name] = s.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := suites[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown or insecure TLS cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
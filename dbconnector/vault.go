@@ -0,0 +1,147 @@
+package dbconnector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// VaultClient reads credentials of the rethinkdb user from HashiCorp Vault
+type VaultClient struct {
+	address   string
+	token     string
+	namespace string
+	client    *http.Client
+}
+
+// VaultSecret is the rethinkdb user read from the vault with its lease
+type VaultSecret struct {
+	Username string
+	Password string
+
+	// LeaseID identifies the lease of dynamic secrets, it is empty for static secrets
+	LeaseID string
+	// LeaseDuration is time the credentials are valid for
+	LeaseDuration time.Duration
+	// Renewable tells if the lease can be extended
+	Renewable bool
+}
+
+// NewVaultClient creates client of the vault at the address authenticated with the token
+func NewVaultClient(address, token, namespace string) *VaultClient {
+	return &VaultClient{
+		address:   strings.TrimRight(address, "/"),
+		token:     token,
+		namespace: namespace,
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type vaultResponse struct {
+	LeaseID       string         `json:"lease_id"`
+	LeaseDuration int            `json:"lease_duration"`
+	Renewable     bool           `json:"renewable"`
+	Data          map[string]any `json:"data"`
+	Errors        []string       `json:"errors"`
+}
+
+// ReadCredentials reads the username and the password from the secret at the path.
+// It supports kv secrets engines of both versions and credentials generated by the database secrets engine.
+func (v *VaultClient) ReadCredentials(ctx context.Context, path, usernameKey, passwordKey string) (*VaultSecret, error) {
+	var res vaultResponse
+	err := v.do(ctx, http.MethodGet, strings.TrimLeft(path, "/"), nil, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	data := res.Data
+	// kv version 2 nests the secret in the data field next to its metadata
+	if nested, ok := data["data"].(map[string]any); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+
+	secret := &VaultSecret{
+		LeaseID:       res.LeaseID,
+		LeaseDuration: time.Duration(res.LeaseDuration) * time.Second,
+		Renewable:     res.Renewable,
+	}
+	secret.Username, err = stringField(data, usernameKey)
+	if err != nil {
+		return nil, err
+	}
+	secret.Password, err = stringField(data, passwordKey)
+	if err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// RenewLease extends the lease of the secret and returns the new duration of the lease
+func (v *VaultClient) RenewLease(ctx context.Context, leaseID string, increment time.Duration) (time.Duration, error) {
+	body := map[string]any{
+		"lease_id":  leaseID,
+		"increment": int(increment.Seconds()),
+	}
+	var res vaultResponse
+	err := v.do(ctx, http.MethodPut, "sys/leases/renew", body, &res)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(res.LeaseDuration) * time.Second, nil
+}
+
+func (v *VaultClient) do(ctx context.Context, method, path string, body any, result *vaultResponse) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, v.address+"/v1/"+path, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+	if v.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("vault request failed: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	err = json.NewDecoder(resp.Body).Decode(result)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to decode vault response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("vault responded with status %d to %s: %s", resp.StatusCode, path, strings.Join(result.Errors, ", "))
+	}
+	return nil
+}
+
+func stringField(data map[string]any, key string) (string, error) {
+	value, ok := data[key].(string)
+	if !ok {
+		return "", fmt.Errorf("vault secret has no string field %q", key)
+	}
+	return value, nil
+}
@@ -0,0 +1,157 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	consulWait       = 5 * time.Minute
+	consulRetryDelay = 5 * time.Second
+	// consulMinInterval rate limits the blocking queries, they may return early without any change
+	consulMinInterval = time.Second
+)
+
+// Consul discovers the nodes by the healthy instances of the consul service,
+// changes are watched with blocking queries
+type Consul struct {
+	log        *slog.Logger
+	address    string
+	service    string
+	datacenter string
+	tags       []string
+	token      string
+	client     *http.Client
+}
+
+// NewConsul creates discovery by the service registered in the consul agent at the address
+func NewConsul(log *slog.Logger, address, service, datacenter string, tags []string, token string) *Consul {
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+	return &Consul{
+		log:        log,
+		address:    strings.TrimRight(address, "/"),
+		service:    service,
+		datacenter: datacenter,
+		tags:       tags,
+		token:      token,
+		// blocking queries are answered after the wait time at latest
+		client: &http.Client{Timeout: consulWait + time.Minute},
+	}
+}
+
+type consulServiceEntry struct {
+	Node struct {
+		Address string
+	}
+	Service struct {
+		Address string
+		Port    int
+	}
+}
+
+// Resolve returns the addresses of the healthy service instances sorted
+func (d *Consul) Resolve(ctx context.Context) ([]string, error) {
+	addresses, _, err := d.query(ctx, 0)
+	return addresses, err
+}
+
+// Watch waits for changes of the service instances with blocking queries
+func (d *Consul) Watch(ctx context.Context, onChange func([]string)) {
+	var last []string
+	var index uint64
+	for ctx.Err() == nil {
+		started := time.Now()
+		addresses, newIndex, err := d.query(ctx, index)
+		if err != nil {
+			if ctx.Err() == nil {
+				d.log.Error("failed to discover rethinkdb nodes", "error", err)
+			}
+			index = 0
+			select {
+			case <-ctx.Done():
+			case <-time.After(consulRetryDelay):
+			}
+			continue
+		}
+
+		if last != nil && !slices.Equal(last, addresses) {
+			d.log.Info("rethinkdb nodes changed", "addresses", addresses)
+			onChange(addresses)
+		}
+		last = addresses
+		// the index may go backwards, e.g. after the consul servers were restored
+		if newIndex < index {
+			newIndex = 0
+		}
+		index = newIndex
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(consulMinInterval - time.Since(started)):
+		}
+	}
+}
+
+func (d *Consul) query(ctx context.Context, index uint64) ([]string, uint64, error) {
+	params := url.Values{}
+	params.Set("passing", "1")
+	if d.datacenter != "" {
+		params.Set("dc", d.datacenter)
+	}
+	for _, tag := range d.tags {
+		params.Add("tag", tag)
+	}
+	if index > 0 {
+		params.Set("index", strconv.FormatUint(index, 10))
+		params.Set("wait", consulWait.String())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		d.address+"/v1/health/service/"+url.PathEscape(d.service)+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if d.token != "" {
+		req.Header.Set("X-Consul-Token", d.token)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("consul request failed: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("consul responded with status %s", resp.Status)
+	}
+
+	var entries []consulServiceEntry
+	err = json.NewDecoder(resp.Body).Decode(&entries)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to decode consul response: %w", err)
+	}
+	newIndex, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+
+	addresses := make([]string, 0, len(entries))
+	for _, e := range entries {
+		host := e.Service.Address
+		if host == "" {
+			host = e.Node.Address
+		}
+		addresses = append(addresses, net.JoinHostPort(host, strconv.Itoa(e.Service.Port)))
+	}
+	slices.Sort(addresses)
+	return addresses, newIndex, nil
+}
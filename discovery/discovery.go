@@ -0,0 +1,47 @@
+// Package discovery finds addresses of the rethinkdb nodes and watches them for changes
+package discovery
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// Discoverer finds addresses of the rethinkdb nodes
+type Discoverer interface {
+	// Resolve returns the current addresses of the nodes
+	Resolve(ctx context.Context) ([]string, error)
+	// Watch calls onChange with the addresses whenever they differ from the last ones until the context is done
+	Watch(ctx context.Context, onChange func([]string))
+}
+
+// poll resolves the addresses on the interval and calls onChange when they change, a zero interval disables it
+func poll(ctx context.Context, log *slog.Logger, interval time.Duration, resolve func(context.Context) ([]string, error), onChange func([]string)) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last []string
+	for {
+		addresses, err := resolve(ctx)
+		switch {
+		case err != nil:
+			log.Error("failed to discover rethinkdb nodes", "error", err)
+		case last != nil && !slices.Equal(last, addresses):
+			log.Info("rethinkdb nodes changed", "addresses", addresses)
+			onChange(addresses)
+			fallthrough
+		default:
+			last = addresses
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
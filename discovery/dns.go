@@ -0,0 +1,49 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"slices"
+	"time"
+)
+
+const defaultPort = "28015"
+
+// DNS discovers the nodes by the addresses of the DNS name, e.g. of a kubernetes headless service
+// resolving to the pods of the rethinkdb stateful set
+type DNS struct {
+	log      *slog.Logger
+	host     string
+	port     string
+	interval time.Duration
+}
+
+// NewDNS creates discovery by the name given as host:port, resolved again on the interval
+func NewDNS(log *slog.Logger, name string, interval time.Duration) *DNS {
+	host, port, err := net.SplitHostPort(name)
+	if err != nil {
+		host, port = name, defaultPort
+	}
+	return &DNS{log: log, host: host, port: port, interval: interval}
+}
+
+// Resolve returns the addresses of the name sorted
+func (d *DNS) Resolve(ctx context.Context) ([]string, error) {
+	ips, err := net.DefaultResolver.LookupHost(ctx, d.host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", d.host, err)
+	}
+	addresses := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		addresses = append(addresses, net.JoinHostPort(ip, d.port))
+	}
+	slices.Sort(addresses)
+	return addresses, nil
+}
+
+// Watch resolves the name on the interval
+func (d *DNS) Watch(ctx context.Context, onChange func([]string)) {
+	poll(ctx, d.log, d.interval, d.Resolve, onChange)
+}
@@ -0,0 +1,76 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"slices"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// File discovers the nodes by the addresses listed in the JSON or YAML file managed by external tooling.
+// The file is either a list of addresses or a list of prometheus file_sd target groups.
+type File struct {
+	log      *slog.Logger
+	path     string
+	interval time.Duration
+}
+
+// NewFile creates discovery by the file, read again on the interval
+func NewFile(log *slog.Logger, path string, interval time.Duration) *File {
+	return &File{log: log, path: path, interval: interval}
+}
+
+// fileTargetGroup is a target group of the prometheus file_sd format, labels are ignored
+type fileTargetGroup struct {
+	Targets []string `yaml:"targets"`
+}
+
+// Resolve returns the addresses of the file sorted, without duplicates
+func (d *File) Resolve(_ context.Context) ([]string, error) {
+	content, err := os.ReadFile(d.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read address file: %w", err)
+	}
+
+	// JSON is valid YAML
+	var nodes []yaml.Node
+	err = yaml.Unmarshal(content, &nodes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse address file %s: %w", d.path, err)
+	}
+
+	var addresses []string
+	for _, node := range nodes {
+		switch node.Kind {
+		case yaml.ScalarNode:
+			addresses = append(addresses, node.Value)
+		case yaml.MappingNode:
+			var group fileTargetGroup
+			err = node.Decode(&group)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse target group in address file %s: %w", d.path, err)
+			}
+			addresses = append(addresses, group.Targets...)
+		default:
+			return nil, fmt.Errorf("address file %s must list addresses or target groups", d.path)
+		}
+	}
+
+	for i, address := range addresses {
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			addresses[i] = net.JoinHostPort(address, defaultPort)
+		}
+	}
+	slices.Sort(addresses)
+	return slices.Compact(addresses), nil
+}
+
+// Watch reads the file on the interval
+func (d *File) Watch(ctx context.Context, onChange func([]string)) {
+	poll(ctx, d.log, d.interval, d.Resolve, onChange)
+}
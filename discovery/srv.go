@@ -0,0 +1,43 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SRV discovers the nodes by the DNS SRV record, e.g. _rethinkdb._tcp.example.com
+type SRV struct {
+	log      *slog.Logger
+	record   string
+	interval time.Duration
+}
+
+// NewSRV creates discovery by the SRV record resolved again on the interval
+func NewSRV(log *slog.Logger, record string, interval time.Duration) *SRV {
+	return &SRV{log: log, record: record, interval: interval}
+}
+
+// Resolve returns the targets of the SRV record sorted by address
+func (d *SRV) Resolve(ctx context.Context) ([]string, error) {
+	_, records, err := net.DefaultResolver.LookupSRV(ctx, "", "", d.record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve srv record %s: %w", d.record, err)
+	}
+	addresses := make([]string, 0, len(records))
+	for _, r := range records {
+		addresses = append(addresses, net.JoinHostPort(strings.TrimSuffix(r.Target, "."), strconv.Itoa(int(r.Port))))
+	}
+	slices.Sort(addresses)
+	return addresses, nil
+}
+
+// Watch resolves the SRV record on the interval
+func (d *SRV) Watch(ctx context.Context, onChange func([]string)) {
+	poll(ctx, d.log, d.interval, d.Resolve, onChange)
+}
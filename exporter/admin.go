@@ -0,0 +1,45 @@
+package exporter
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// initAdmin serves the health, readiness and admin endpoints on the exporter's http-server
+// or on a separate one if the admin address is set
+func (e *RethinkdbExporter) initAdmin() {
+	mux := e.mux
+	if e.opts.AdminAddress != "" {
+		mux = http.NewServeMux()
+	}
+	if e.opts.OnReload != nil {
+		mux.Handle("/-/reload", e.requireToken(http.HandlerFunc(e.reloadHandler)))
+	}
+	if e.opts.LogLevel != nil {
+		mux.Handle("/-/loglevel", e.requireToken(http.HandlerFunc(e.logLevelHandler)))
+	}
+	mux.HandleFunc("/-/healthy", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = fmt.Fprintf(w, "OK")
+	})
+	mux.HandleFunc("/-/ready", e.readyHandler)
+	if e.opts.AdminAddress == "" {
+		return
+	}
+
+	var handler http.Handler = mux
+	if e.opts.AccessLog {
+		handler = e.accessLog(handler)
+	}
+	e.adminServer = e.newServer(e.opts.AdminAddress, handler)
+}
+
+// listenAndServeAdmin runs the separate http-server of the admin endpoints until it is shut down
+func (e *RethinkdbExporter) listenAndServeAdmin() {
+	e.log.Info("serving admin endpoints", "address", e.opts.AdminAddress)
+	err := e.adminServer.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		e.log.Error("failed to serve admin endpoints", "error", err)
+	}
+}
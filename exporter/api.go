@@ -0,0 +1,106 @@
+package exporter
+
+import (
+	"encoding/json"
+	"net/http"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// apiStats are the collected metrics grouped by the cluster, servers and tables they describe
+type apiStats struct {
+	// Cluster are the metrics without the server and table labels by metric name
+	Cluster map[string][]apiSample `json:"cluster"`
+	// Servers are the metrics with the server label but without the table label by server and metric name
+	Servers map[string]map[string][]apiSample `json:"servers"`
+	// Tables are the metrics with the db and table labels by db.table and metric name,
+	// the metrics of the table replicas keep the server label
+	Tables map[string]map[string][]apiSample `json:"tables"`
+}
+
+// apiSample is a value of the metric with the labels besides the ones it is grouped by
+type apiSample struct {
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  float64           `json:"value"`
+}
+
+// apiStatsHandler serves the collected rethinkdb metrics as JSON for the tools not parsing the prometheus format.
+// They are collected like for a scrape, so they are served from the cache in the background collection mode.
+func (e *RethinkdbExporter) apiStatsHandler(w http.ResponseWriter, r *http.Request) {
+	gatherer, err := e.gatherer(r.Context(), false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	families, err := gatherer.Gather()
+	if err != nil {
+		e.log.Warn("stats gathered with errors", "error", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(e.apiStats(families))
+	if err != nil {
+		e.log.Error("failed to write stats", "error", err)
+	}
+}
+
+// apiStats groups the gauges, counters and untyped metrics by the labels of the servers and tables,
+// the other types and the values which are not finite are skipped
+func (e *RethinkdbExporter) apiStats(families []*dto.MetricFamily) apiStats {
+	var (
+		serverLabel = e.labelName("server")
+		dbLabel     = e.labelName("db")
+		tableLabel  = e.labelName("table")
+	)
+	stats := apiStats{
+		Cluster: map[string][]apiSample{},
+		Servers: map[string]map[string][]apiSample{},
+		Tables:  map[string]map[string][]apiSample{},
+	}
+	add := func(groups map[string]map[string][]apiSample, group, name string, sample apiSample) {
+		if groups[group] == nil {
+			groups[group] = map[string][]apiSample{}
+		}
+		groups[group][name] = append(groups[group][name], sample)
+	}
+
+	for _, f := range families {
+		for _, m := range f.GetMetric() {
+			var value float64
+			switch f.GetType() {
+			case dto.MetricType_GAUGE:
+				value = m.GetGauge().GetValue()
+			case dto.MetricType_COUNTER:
+				value = m.GetCounter().GetValue()
+			case dto.MetricType_UNTYPED:
+				value = m.GetUntyped().GetValue()
+			default:
+				continue
+			}
+			if !finite(value) {
+				continue
+			}
+
+			labels := make(map[string]string, len(m.GetLabel()))
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			db, hasDB := labels[dbLabel]
+			table, hasTable := labels[tableLabel]
+			server, hasServer := labels[serverLabel]
+			sample := apiSample{Labels: labels, Value: value}
+			switch {
+			case hasDB && hasTable:
+				delete(labels, dbLabel)
+				delete(labels, tableLabel)
+				add(stats.Tables, db+"."+table, f.GetName(), sample)
+			case hasServer:
+				delete(labels, serverLabel)
+				add(stats.Servers, server, f.GetName(), sample)
+			default:
+				stats.Cluster[f.GetName()] = append(stats.Cluster[f.GetName()], sample)
+			}
+		}
+	}
+	return stats
+}
@@ -0,0 +1,39 @@
+package exporter
+
+import (
+	"sync"
+	"time"
+)
+
+// circuitBreaker stops querying the cluster for a cooldown after consecutive failed scrapes,
+// so scrapes of a down cluster don't wait for the timeout. After the cooldown the next scrape
+// queries the cluster again, the breaker is closed if it succeeds and opened again otherwise.
+type circuitBreaker struct {
+	m         sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// allow reports whether the scrape may query the cluster
+func (b *circuitBreaker) allow() bool {
+	b.m.Lock()
+	defer b.m.Unlock()
+	return !time.Now().Before(b.openUntil)
+}
+
+// record counts the consecutive failed scrapes and returns true if the breaker was opened by the failure
+func (b *circuitBreaker) record(up bool, threshold int, cooldown time.Duration) bool {
+	b.m.Lock()
+	defer b.m.Unlock()
+	if up {
+		b.failures = 0
+		b.openUntil = time.Time{}
+		return false
+	}
+	b.failures++
+	if b.failures < threshold {
+		return false
+	}
+	b.openUntil = time.Now().Add(cooldown)
+	return true
+}
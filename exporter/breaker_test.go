@@ -0,0 +1,90 @@
+package exporter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold int
+		cooldown  time.Duration
+		results   []bool
+		wantTrips []bool
+		wantAllow bool
+	}{
+		{
+			name:      "successes",
+			threshold: 3,
+			cooldown:  time.Hour,
+			results:   []bool{true, true},
+			wantTrips: []bool{false, false},
+			wantAllow: true,
+		},
+		{
+			name:      "failures below threshold",
+			threshold: 3,
+			cooldown:  time.Hour,
+			results:   []bool{false, false},
+			wantTrips: []bool{false, false},
+			wantAllow: true,
+		},
+		{
+			name:      "opened at threshold",
+			threshold: 3,
+			cooldown:  time.Hour,
+			results:   []bool{false, false, false},
+			wantTrips: []bool{false, false, true},
+			wantAllow: false,
+		},
+		{
+			name:      "success resets failures",
+			threshold: 2,
+			cooldown:  time.Hour,
+			results:   []bool{false, true, false},
+			wantTrips: []bool{false, false, false},
+			wantAllow: true,
+		},
+		{
+			name:      "failures over threshold open it again",
+			threshold: 2,
+			cooldown:  time.Hour,
+			results:   []bool{false, false, false},
+			wantTrips: []bool{false, true, true},
+			wantAllow: false,
+		},
+		{
+			name:      "success closes",
+			threshold: 1,
+			cooldown:  time.Hour,
+			results:   []bool{false, true},
+			wantTrips: []bool{true, false},
+			wantAllow: true,
+		},
+		{
+			name:      "cooldown elapsed",
+			threshold: 1,
+			cooldown:  -time.Second,
+			results:   []bool{false},
+			wantTrips: []bool{true},
+			wantAllow: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &circuitBreaker{}
+			if !b.allow() {
+				t.Fatal("new breaker is open")
+			}
+			for i, up := range tt.results {
+				if got := b.record(up, tt.threshold, tt.cooldown); got != tt.wantTrips[i] {
+					t.Errorf("record(%v) #%d = %v, want %v", up, i, got, tt.wantTrips[i])
+				}
+			}
+			if got := b.allow(); got != tt.wantAllow {
+				t.Errorf("allow() = %v, want %v", got, tt.wantAllow)
+			}
+		})
+	}
+}
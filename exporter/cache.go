@@ -0,0 +1,123 @@
+package exporter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metricsCache keeps the metrics collected in the background, so scrapes don't query the rethinkdb
+type metricsCache struct {
+	m       sync.RWMutex
+	metrics []prometheus.Metric
+	updated time.Time
+}
+
+func (c *metricsCache) set(metrics []prometheus.Metric) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.metrics = metrics
+	c.updated = time.Now()
+}
+
+func (c *metricsCache) get() ([]prometheus.Metric, time.Time) {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	return c.metrics, c.updated
+}
+
+// enableCache serves the scrapes from the cache instead of collecting the metrics
+func (e *RethinkdbExporter) enableCache() {
+	e.cache = &metricsCache{}
+	e.cacheAge = prometheus.NewDesc(
+		prometheus.BuildFQName(e.namespace, exporterSubsystem, "cache_age_seconds"),
+		"Age of the cached metrics collected in the background",
+		nil, e.opts.Labels)
+}
+
+// collectToCache collects the metrics once and replaces the cached ones unless the context is done
+func (e *RethinkdbExporter) collectToCache(ctx context.Context) {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		c, release := e.acquire()
+		defer release()
+		c.collect(ctx, ch)
+		close(ch)
+	}()
+	var metrics []prometheus.Metric
+	for m := range ch {
+		metrics = append(metrics, m)
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	e.cache.set(metrics)
+}
+
+// collectLoop collects metrics into the cache on the collect interval until the context is done
+func (e *RethinkdbExporter) collectLoop(ctx context.Context) {
+	ticker := time.NewTicker(e.opts.CollectInterval)
+	defer ticker.Stop()
+
+	for {
+		e.collectToCache(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// cachedCollector serves the metrics from the cache of the exporter
+type cachedCollector struct {
+	*RethinkdbExporter
+}
+
+// Describe sends metrics descriptions to the prometheus chan
+func (c cachedCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.current().Describe(ch)
+	ch <- c.cacheAge
+}
+
+// Collect sends the cached metrics values to the prometheus chan.
+// Only rethinkdb_up of the clusters is reported as 0 if the cache is older than the max staleness.
+func (c cachedCollector) Collect(ch chan<- prometheus.Metric) {
+	metrics, updated := c.cache.get()
+	if updated.IsZero() {
+		return
+	}
+
+	age := time.Since(updated)
+	ch <- prometheus.MustNewConstMetric(c.cacheAge, prometheus.GaugeValue, age.Seconds())
+
+	current := c.current()
+	if current.opts.MaxStaleness > 0 && age > current.opts.MaxStaleness {
+		c.log.Warn("cached metrics are stale", "age", age, "max_staleness", current.opts.MaxStaleness)
+		ch <- prometheus.MustNewConstMetric(current.up, prometheus.GaugeValue, 0)
+		for _, cluster := range current.clusters {
+			ch <- prometheus.MustNewConstMetric(cluster.up, prometheus.GaugeValue, 0)
+		}
+		return
+	}
+	for _, m := range metrics {
+		ch <- m
+	}
+}
+
+// CollectOnce collects the metrics once and serves them from the cache afterwards,
+// so the following scrapes don't query the cluster again. The background collection must not be enabled.
+// It returns an error if any collector failed or a cluster couldn't be queried.
+func (e *RethinkdbExporter) CollectOnce(ctx context.Context) error {
+	if e.cache == nil {
+		e.enableCache()
+	}
+	e.collectToCache(ctx)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return e.lastScrapeError()
+}
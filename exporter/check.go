@@ -0,0 +1,92 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+
+	r "gopkg.in/rethinkdb/rethinkdb-go.v6"
+)
+
+// collectorSystemTables are the system tables read by the collectors, the user needs read access to them
+var collectorSystemTables = map[string][]string{
+	"stats":          {r.StatsSystemTable, r.ServerConfigSystemTable},
+	"db_config":      {r.DBConfigSystemTable, r.TableConfigSystemTable},
+	"server_status":  {r.ServerStatusSystemTable},
+	"table_status":   {r.TableStatusSystemTable},
+	"table_config":   {r.TableConfigSystemTable},
+	"index_status":   {r.TableConfigSystemTable},
+	"cluster_config": {r.ClusterConfigSystemTable},
+	"current_issues": {r.CurrentIssuesSystemTable},
+	"jobs":           {r.JobsSystemTable},
+	"logs":           {r.LogsSystemTable},
+	"users":          {r.UsersSystemTable, r.PermissionsSystemTable},
+}
+
+// Check validates the options and checks that the clusters can be queried
+// and the user can read the system tables of the enabled collectors and the tables counted exactly.
+// The returned error describes what has to be fixed.
+func Check(ctx context.Context, log *slog.Logger, rconn r.QueryExecutor, opts Options) error {
+	err := validateOptions(opts)
+	if err != nil {
+		return fmt.Errorf("invalid options: %w", err)
+	}
+	_, err = newOutputs(opts)
+	if err != nil {
+		return fmt.Errorf("invalid outputs: %w", err)
+	}
+
+	e := newCollector(log, rconn, opts)
+	errs := []error{e.check(ctx)}
+	for _, c := range e.clusters {
+		errs = append(errs, c.check(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// check queries the cluster and the tables read by the collectors
+func (e *RethinkdbExporter) check(ctx context.Context) error {
+	cluster := "cluster"
+	if e.opts.ClusterName != "" {
+		cluster = fmt.Sprintf("cluster %s", e.opts.ClusterName)
+	}
+
+	var res int
+	err := e.readOne(ctx, "ping", r.Expr(1), &res)
+	if err != nil {
+		return fmt.Errorf("%s can't be queried, check the addresses, the credentials and the tls options: %w", cluster, err)
+	}
+
+	readers := make(map[string][]string)
+	for name := range e.collectors {
+		for _, table := range collectorSystemTables[name] {
+			readers[table] = append(readers[table], name)
+		}
+	}
+	var errs []error
+	for _, table := range slices.Sorted(maps.Keys(readers)) {
+		collectors := readers[table]
+		slices.Sort(collectors)
+		var docs []interface{}
+		err = e.readAll(ctx, table, e.systemTable(table).Limit(1), &docs)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: can't read %s.%s needed by the %s collectors, grant the user read permission on the %s database: %w",
+				cluster, r.SystemDatabase, table, strings.Join(collectors, ", "), r.SystemDatabase, err))
+		}
+	}
+	if _, ok := e.collectors["table_rows_exact"]; ok {
+		for _, t := range e.opts.ExactCountTables {
+			dbName, tableName, _ := strings.Cut(t, ".")
+			var docs []interface{}
+			err = e.readAll(ctx, "table_count", r.DB(dbName).Table(tableName).Limit(1), &docs)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: can't read %s counted exactly, grant the user read permission on it or remove it: %w", cluster, t, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,56 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	r "gopkg.in/rethinkdb/rethinkdb-go.v6"
+)
+
+func init() {
+	registerCollector("cluster_config", true, newClusterConfigCollector)
+}
+
+// clusterConfigCollector collects cluster-wide settings from the rethinkdb.cluster_config system table
+type clusterConfigCollector struct {
+	e *RethinkdbExporter
+
+	heartbeatTimeout *prometheus.Desc
+}
+
+func newClusterConfigCollector(e *RethinkdbExporter) collector {
+	return &clusterConfigCollector{
+		e: e,
+		heartbeatTimeout: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "cluster", "heartbeat_timeout_seconds"),
+			"Time after which the servers consider an unresponsive server disconnected"),
+	}
+}
+
+type clusterConfig struct {
+	ID                   string  `rethinkdb:"id"`
+	HeartbeatTimeoutSecs float64 `rethinkdb:"heartbeat_timeout_secs"`
+}
+
+// Describe sends metrics descriptions to the prometheus chan
+func (c *clusterConfigCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.heartbeatTimeout
+}
+
+// Update sends collected metrics values to the prometheus chan
+func (c *clusterConfigCollector) Update(ctx context.Context, ch chan<- prometheus.Metric) error {
+	var configs []clusterConfig
+	err := c.e.readAll(ctx, r.ClusterConfigSystemTable, c.e.systemTable(r.ClusterConfigSystemTable), &configs)
+	if err != nil {
+		return fmt.Errorf("failed to query system cluster_config table: %w", err)
+	}
+
+	for _, config := range configs {
+		switch config.ID {
+		case "heartbeat":
+			ch <- prometheus.MustNewConstMetric(c.heartbeatTimeout, prometheus.GaugeValue, config.HeartbeatTimeoutSecs)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package exporter
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// defaultClusterLabel is name of the label distinguishing metrics of the scraped clusters
+const defaultClusterLabel = "cluster"
+
+// initClusters creates collectors of the other clusters from the options.
+// The exporter's own metrics of scraping them are labeled by cluster, see clusterSelf.
+func (e *RethinkdbExporter) initClusters() {
+	e.clusters = nil
+	for _, name := range slices.Sorted(maps.Keys(e.opts.Clusters)) {
+		opts := e.opts
+		opts.Clusters = nil
+		opts.ClusterName = name
+		c := newCollector(e.log.With("cluster", name), e.opts.Clusters[name], opts)
+		c.self = e.clusterSelf(nil, name)
+		e.clusters = append(e.clusters, c)
+	}
+}
+
+// clusterSelf returns the exporter's own metrics of scraping the other cluster,
+// those of the previous collector are kept so reloads don't reset them.
+// They share the metrics of the exporter process and are labeled by the cluster name.
+// Without a cluster name of the exporter they are shared completely,
+// the same metrics can't be exported both with and without the cluster label.
+func (e *RethinkdbExporter) clusterSelf(previous *RethinkdbExporter, name string) *selfMetrics {
+	if e.opts.ClusterName == "" {
+		return e.self
+	}
+	if previous != nil {
+		for _, c := range previous.clusters {
+			if c.opts.ClusterName == name {
+				return c.self
+			}
+		}
+	}
+	opts := e.opts
+	opts.ClusterName = name
+	self := *e.self
+	self.clusterSelfMetrics = newClusterSelfMetrics(opts)
+	return &self
+}
+
+// clustersSelfCollector collects the exporter's own metrics of scraping the other clusters,
+// they change with the clusters on reload so they are not described in advance
+type clustersSelfCollector struct {
+	e *RethinkdbExporter
+}
+
+// Describe sends no descriptions, the collector is unchecked
+func (c clustersSelfCollector) Describe(chan<- *prometheus.Desc) {}
+
+// Collect sends metrics values to the prometheus chan
+func (c clustersSelfCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, cluster := range c.e.current().clusters {
+		if cluster.self != c.e.self {
+			cluster.self.clusterSelfMetrics.Collect(ch)
+		}
+	}
+}
+
+// clusterLabel returns name of the cluster label according to the options
+func clusterLabel(opts Options) string {
+	if opts.ClusterLabel == "" {
+		return defaultClusterLabel
+	}
+	return opts.ClusterLabel
+}
+
+// validateLabels checks that the static labels don't clash with the cluster label
+func validateLabels(opts Options) error {
+	if len(opts.Clusters) == 0 {
+		return nil
+	}
+	if _, ok := opts.Labels[clusterLabel(opts)]; ok {
+		return fmt.Errorf("static label %q clashes with the cluster label", clusterLabel(opts))
+	}
+	return nil
+}
@@ -3,177 +3,165 @@ package exporter
 import (
 	"context"
 	"errors"
-	"fmt"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"golang.org/x/sync/errgroup"
 	r "gopkg.in/rethinkdb/rethinkdb-go.v6"
 )
 
 // Collect send collected metrics values to the prometheus chan
 func (e *RethinkdbExporter) Collect(ch chan<- prometheus.Metric) {
-	start := time.Now()
+	e.collect(context.Background(), ch)
+}
 
-	ctx := context.TODO() // TODO: add scrape timeout
-	errcount := e.collectRethinkStats(ctx, ch)
+// requestCollector collects metrics within the context of the http request,
+// so the queries are cancelled when the scraping client goes away
+type requestCollector struct {
+	*RethinkdbExporter
+	ctx context.Context
+}
 
-	elapsed := time.Since(start)
-	ch <- prometheus.MustNewConstMetric(e.metrics.scrapeErrors, prometheus.GaugeValue, float64(errcount))
-	ch <- prometheus.MustNewConstMetric(e.metrics.scrapeLatency, prometheus.GaugeValue, elapsed.Seconds())
+// Collect send collected metrics values to the prometheus chan,
+// the connection is not reported as released by Reconfigure until they are collected
+func (c requestCollector) Collect(ch chan<- prometheus.Metric) {
+	c.inUse.RLock()
+	defer c.inUse.RUnlock()
+	c.collect(c.ctx, ch)
+}
 
-	e.log.Debug("collect finished", "duration", elapsed)
+// collect collects metrics of the cluster and of the other clusters concurrently
+func (e *RethinkdbExporter) collect(ctx context.Context, ch chan<- prometheus.Metric) {
+	wg := sync.WaitGroup{}
+	for _, c := range e.clusters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.collectCluster(ctx, ch)
+		}()
+	}
+	e.collectCluster(ctx, ch)
+	wg.Wait()
 }
 
-func (e *RethinkdbExporter) collectRethinkStats(ctx context.Context, ch chan<- prometheus.Metric) int {
-	errcount := 0
+func (e *RethinkdbExporter) collectCluster(ctx context.Context, ch chan<- prometheus.Metric) {
+	start := time.Now()
+	e.startStreams()
 
-	cur, err := r.DB(r.SystemDatabase).Table(r.StatsSystemTable).Run(e.rconn, r.RunOpts{Context: ctx})
-	if err != nil {
-		e.log.Error("failed to query system stats table", "error", err)
-		errcount++
-		return errcount
+	if e.opts.BreakerThreshold > 0 && !e.breaker.allow() {
+		e.log.Debug("circuit breaker is open, cluster is not queried")
+		ch <- prometheus.MustNewConstMetric(e.up, prometheus.GaugeValue, 0)
+		e.health.record(false)
+		return
 	}
-	defer func() {
-		err := cur.Close()
-		if err != nil {
-			e.log.Warn("error while closing cursor", "error", err)
-		}
-	}()
-
-	if cur.Err() != nil {
-		e.log.Error("query error from cursor", "error", err)
-		errcount++
-		return errcount
+
+	ch, flush := e.legacyChan(ch)
+	defer flush()
+
+	if e.scrapeTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.scrapeTimeout)
+		defer cancel()
 	}
 
-	wg := &errgroup.Group{}
-	var stat stat
-	for cur.Next(&stat) {
-		if cur.Err() != nil {
-			e.log.Error("query error from cursor", "error", err)
-			errcount++
-			return errcount
-		}
-
-		err = e.processStat(ctx, stat, wg, ch)
-		if err != nil {
-			e.log.Warn("error while processing stat", "error", err)
-			errcount++
-		}
+	var statsErr error
+	wg := sync.WaitGroup{}
+	for name, c := range e.collectors {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := e.runCollector(ctx, name, c, ch)
+			for _, t := range errorTypes(err) {
+				e.self.scrapeErrors.WithLabelValues(t).Inc()
+			}
+			if name == "stats" {
+				statsErr = err
+			}
+		}()
 	}
-	err = wg.Wait()
-	if err != nil {
-		e.log.Warn("error while processing stat", "error", err)
-		errcount++
+	wg.Wait()
+
+	// the cluster is up if its stats could be read, failures of the other collectors
+	// and of the table info queries are reported by collector_success only
+	var up bool
+	if _, ok := e.collectors["stats"]; ok {
+		up = !slices.ContainsFunc(errorTypes(statsErr), func(t string) bool { return t != tableInfoError })
+	} else {
+		up = e.ping(ctx)
+	}
+	ch <- prometheus.MustNewConstMetric(e.up, prometheus.GaugeValue, boolToFloat(up))
+	e.health.record(up)
+	if e.opts.BreakerThreshold > 0 {
+		e.recordBreaker(up)
 	}
 
-	return errcount
-}
+	elapsed := time.Since(start)
+	e.self.scrapeDuration.Observe(elapsed.Seconds())
+	if e.self.scrapeLatency != nil {
+		e.self.scrapeLatency.Set(elapsed.Seconds())
+	}
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		e.log.Warn("scrape timeout reached", "timeout", e.scrapeTimeout)
+		e.self.scrapeTimedOut.Set(1)
+	case errors.Is(ctx.Err(), context.Canceled):
+		e.log.Warn("scrape cancelled by the client")
+		e.self.scrapeTimedOut.Set(0)
+	default:
+		e.self.scrapeTimedOut.Set(0)
+	}
 
-type stat struct {
-	ID            []string      `rethinkdb:"id"`
-	Server        string        `rethinkdb:"server"`
-	Database      string        `rethinkdb:"db"`
-	Table         string        `rethinkdb:"table"`
-	QueryEngine   queryEngine   `rethinkdb:"query_engine"`
-	StorageEngine storageEngine `rethinkdb:"storage_engine"`
+	e.log.Debug("collect finished", "duration", elapsed)
 }
 
-type queryEngine struct {
-	ClientConnections float64 `rethinkdb:"client_connections"`
-	QPS               float64 `rethinkdb:"queries_per_sec"`
-	ReadDocsPerSec    float64 `rethinkdb:"read_docs_per_sec"`
-	WrittenDocsPerSec float64 `rethinkdb:"written_docs_per_sec"`
+// recordBreaker opens the circuit breaker after consecutive failures and reports its state
+func (e *RethinkdbExporter) recordBreaker(up bool) {
+	switch {
+	case e.breaker.record(up, e.opts.BreakerThreshold, e.opts.BreakerCooldown):
+		e.log.Warn("circuit breaker opened, cluster is not queried for the cooldown", "cooldown", e.opts.BreakerCooldown)
+		e.self.breakerTrips.Inc()
+		e.self.breakerOpen.Set(1)
+	case up:
+		e.self.breakerOpen.Set(0)
+	}
 }
 
-type storageEngine struct {
-	Cache struct {
-		InUseBytes float64 `rethinkdb:"in_use_bytes"`
-	} `rethinkdb:"cache"`
-	Disk struct {
-		ReadBytesPerSec    float64 `rethinkdb:"read_bytes_per_sec"`
-		WrittenBytesPerSec float64 `rethinkdb:"written_bytes_per_sec"`
-		SpaceUsage         struct {
-			DataBytes float64 `rethinkdb:"data_bytes"`
-		} `rethinkdb:"space_usage"`
-	} `rethinkdb:"disk"`
+// runCollector updates metrics of the collector
+func (e *RethinkdbExporter) runCollector(ctx context.Context, name string, c collector, ch chan<- prometheus.Metric) error {
+	return e.runPhase(name, func() error {
+		return c.Update(ctx, ch)
+	})
 }
 
-type info struct {
-	DocCountEstimates []float64 `rethinkdb:"doc_count_estimates"`
+// runPhase runs a collector or a part of it, reporting its success and duration by name
+func (e *RethinkdbExporter) runPhase(name string, phase func() error) error {
+	start := time.Now()
+	err := phase()
+	return e.recordPhase(name, time.Since(start), err)
 }
 
-func (e *RethinkdbExporter) processStat(ctx context.Context, stat stat, wg *errgroup.Group, ch chan<- prometheus.Metric) error {
-	if len(stat.ID) == 0 {
-		return errors.New("unexpected empty stat id")
-	}
-	switch stat.ID[0] {
-	case "cluster":
-		e.processClusterStat(stat, ch)
-	case "server":
-		e.processServerStat(stat, ch)
-	case "table":
-		e.processTableStat(ctx, stat, wg, ch)
-	case "table_server":
-		e.processTableServerStat(stat, ch)
-	default:
-		return fmt.Errorf("unexpected stat id: '%v'", stat.ID[0])
+// recordPhase reports success and duration of a collector or a part of it timed by the caller
+func (e *RethinkdbExporter) recordPhase(name string, elapsed time.Duration, err error) error {
+	e.self.collectorDuration.WithLabelValues(name).Set(elapsed.Seconds())
+	if err != nil {
+		e.log.Error("collector failed", "collector", name, "duration", elapsed, "error", err)
+		e.self.collectorSuccess.WithLabelValues(name).Set(0)
+		return err
 	}
-	return nil
-}
-
-func (e *RethinkdbExporter) processClusterStat(stat stat, ch chan<- prometheus.Metric) {
-	ch <- prometheus.MustNewConstMetric(e.metrics.clusterClientConnections, prometheus.GaugeValue, stat.QueryEngine.ClientConnections)
 
-	ch <- prometheus.MustNewConstMetric(e.metrics.clusterDocsPerSecond, prometheus.GaugeValue, stat.QueryEngine.ReadDocsPerSec, readOperation)
-	ch <- prometheus.MustNewConstMetric(e.metrics.clusterDocsPerSecond, prometheus.GaugeValue, stat.QueryEngine.WrittenDocsPerSec, writtenOperation)
-}
-
-func (e *RethinkdbExporter) processServerStat(stat stat, ch chan<- prometheus.Metric) {
-	ch <- prometheus.MustNewConstMetric(e.metrics.serverClientConnections, prometheus.GaugeValue, stat.QueryEngine.ClientConnections, stat.Server)
-
-	ch <- prometheus.MustNewConstMetric(e.metrics.serverDocsPerSecond, prometheus.GaugeValue, stat.QueryEngine.ReadDocsPerSec, stat.Server, readOperation)
-	ch <- prometheus.MustNewConstMetric(e.metrics.serverDocsPerSecond, prometheus.GaugeValue, stat.QueryEngine.WrittenDocsPerSec, stat.Server, writtenOperation)
-
-	ch <- prometheus.MustNewConstMetric(e.metrics.serverQueriesPerSecond, prometheus.GaugeValue, stat.QueryEngine.ReadDocsPerSec, stat.Server)
+	e.log.Debug("collector succeeded", "collector", name, "duration", elapsed)
+	e.self.collectorSuccess.WithLabelValues(name).Set(1)
+	return nil
 }
 
-func (e *RethinkdbExporter) processTableStat(ctx context.Context, stat stat, wg *errgroup.Group, ch chan<- prometheus.Metric) {
-	ch <- prometheus.MustNewConstMetric(e.metrics.tableDocsPerSecond, prometheus.GaugeValue, stat.QueryEngine.ReadDocsPerSec, stat.Database, stat.Table, readOperation)
-	ch <- prometheus.MustNewConstMetric(e.metrics.tableDocsPerSecond, prometheus.GaugeValue, stat.QueryEngine.WrittenDocsPerSec, stat.Database, stat.Table, writtenOperation)
-
-	if e.metrics.tableRowsCount != nil {
-		dbName := stat.Database
-		tableName := stat.Table
-
-		wg.Go(func() error {
-			var info info
-			err := r.DB(dbName).Table(tableName).Info().ReadOne(&info, e.rconn, r.RunOpts{Context: ctx})
-			if err != nil {
-				e.log.Warn("failed to get table info", "db", dbName, "table", tableName, "error", err)
-				return err
-			}
-
-			sum := 0.0
-			for _, e := range info.DocCountEstimates {
-				sum += float64(e)
-			}
-
-			ch <- prometheus.MustNewConstMetric(e.metrics.tableRowsCount, prometheus.GaugeValue, sum, dbName, tableName)
-			return nil
-		})
+// ping checks the connection to the cluster when the stats collector is disabled
+func (e *RethinkdbExporter) ping(ctx context.Context) bool {
+	var res int
+	err := e.readOne(ctx, "ping", r.Expr(1), &res)
+	if err != nil {
+		e.log.Error("failed to query cluster", "error", err)
+		return false
 	}
-}
-
-func (e *RethinkdbExporter) processTableServerStat(stat stat, ch chan<- prometheus.Metric) {
-	ch <- prometheus.MustNewConstMetric(e.metrics.tableReplicaDocsPerSecond, prometheus.GaugeValue, stat.QueryEngine.ReadDocsPerSec, stat.Database, stat.Table, stat.Server, readOperation)
-	ch <- prometheus.MustNewConstMetric(e.metrics.tableReplicaDocsPerSecond, prometheus.GaugeValue, stat.QueryEngine.WrittenDocsPerSec, stat.Database, stat.Table, stat.Server, writtenOperation)
-
-	ch <- prometheus.MustNewConstMetric(e.metrics.tableReplicaCacheBytes, prometheus.GaugeValue, stat.StorageEngine.Cache.InUseBytes, stat.Database, stat.Table, stat.Server)
-
-	ch <- prometheus.MustNewConstMetric(e.metrics.tableReplicaIO, prometheus.GaugeValue, stat.StorageEngine.Disk.ReadBytesPerSec, stat.Database, stat.Table, stat.Server, readOperation)
-	ch <- prometheus.MustNewConstMetric(e.metrics.tableReplicaIO, prometheus.GaugeValue, stat.StorageEngine.Disk.WrittenBytesPerSec, stat.Database, stat.Table, stat.Server, writtenOperation)
-
-	ch <- prometheus.MustNewConstMetric(e.metrics.tableReplicaDataBytes, prometheus.GaugeValue, stat.StorageEngine.Disk.SpaceUsage.DataBytes, stat.Database, stat.Table, stat.Server)
+	return true
 }
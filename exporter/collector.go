@@ -0,0 +1,139 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	r "gopkg.in/rethinkdb/rethinkdb-go.v6"
+)
+
+// outdatedReadMode lets queries read from any replica without waiting for the primary
+const outdatedReadMode = "outdated"
+
+// collector collects a group of metrics, usually from one of the rethinkdb system tables
+type collector interface {
+	// Describe sends descriptions of the collector's metrics to the prometheus chan
+	Describe(ch chan<- *prometheus.Desc)
+	// Update collects the metrics and sends their values to the prometheus chan
+	Update(ctx context.Context, ch chan<- prometheus.Metric) error
+}
+
+type collectorFactory struct {
+	enabledByDefault bool
+	create           func(e *RethinkdbExporter) collector
+}
+
+var collectorFactories = map[string]collectorFactory{}
+
+// registerCollector makes the collector available under the name.
+// It is supposed to be called from init functions of the collectors.
+func registerCollector(name string, enabledByDefault bool, create func(e *RethinkdbExporter) collector) {
+	collectorFactories[name] = collectorFactory{
+		enabledByDefault: enabledByDefault,
+		create:           create,
+	}
+}
+
+// Collectors returns names of all available collectors mapped to whether they are enabled by default
+func Collectors() map[string]bool {
+	collectors := make(map[string]bool, len(collectorFactories))
+	for name, factory := range collectorFactories {
+		collectors[name] = factory.enabledByDefault
+	}
+	return collectors
+}
+
+func validateCollectors(enabled map[string]bool) error {
+	for name := range enabled {
+		if _, ok := collectorFactories[name]; !ok {
+			return fmt.Errorf("unknown collector %q", name)
+		}
+	}
+	return nil
+}
+
+// initCollectors creates the collectors enabled in the options
+func (e *RethinkdbExporter) initCollectors() {
+	e.collectors = make(map[string]collector)
+	for name, factory := range collectorFactories {
+		enabled, ok := e.opts.Collectors[name]
+		if !ok {
+			enabled = factory.enabledByDefault
+		}
+		if enabled {
+			e.collectors[name] = factory.create(e)
+		}
+	}
+}
+
+// systemTable returns the term of the table from the rethinkdb system database
+func (e *RethinkdbExporter) systemTable(name string) r.Term {
+	return r.DB(r.SystemDatabase).Table(name)
+}
+
+// runOpts returns options of the queries run within the context,
+// they read outdated data if it is enabled, so they don't compete with the clients for up-to-date reads
+func (e *RethinkdbExporter) runOpts(ctx context.Context) r.RunOpts {
+	opts := r.RunOpts{Context: ctx}
+	if e.opts.OutdatedReads {
+		opts.ReadMode = outdatedReadMode
+	}
+	return opts
+}
+
+// readAll runs the query and decodes all of its results into the result slice.
+// Duration of the query is observed by the name.
+func (e *RethinkdbExporter) readAll(ctx context.Context, query string, term r.Term, result interface{}) error {
+	defer e.startQuery(query)()
+	return e.withRetries(ctx, query, func() error {
+		return term.ReadAll(result, e.rconn, e.runOpts(ctx))
+	})
+}
+
+// readOne runs the query and decodes its first result, its duration is observed by the name
+func (e *RethinkdbExporter) readOne(ctx context.Context, query string, term r.Term, result interface{}) error {
+	defer e.startQuery(query)()
+	return e.withRetries(ctx, query, func() error {
+		return term.ReadOne(result, e.rconn, e.runOpts(ctx))
+	})
+}
+
+// withRetries runs the query again after connection errors up to the configured number of retries,
+// waiting with exponential backoff between the attempts
+func (e *RethinkdbExporter) withRetries(ctx context.Context, query string, run func() error) error {
+	backoff := e.opts.QueryRetryBackoff
+	for attempt := 1; ; attempt++ {
+		err := run()
+		if err == nil || attempt > e.opts.QueryRetries || errorType(err) != connectionError {
+			return err
+		}
+
+		e.log.Debug("retrying query", "query", query, "attempt", attempt, "backoff", backoff, "error", err)
+		e.self.queryRetries.WithLabelValues(query).Inc()
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// startQuery counts the query in flight until the returned function is called, which observes its duration
+func (e *RethinkdbExporter) startQuery(query string) func() {
+	start := time.Now()
+	e.self.queriesInFlight.Inc()
+	return func() {
+		e.self.queriesInFlight.Dec()
+		e.self.queryDuration.WithLabelValues(query).Observe(time.Since(start).Seconds())
+	}
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,64 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	r "gopkg.in/rethinkdb/rethinkdb-go.v6"
+)
+
+func init() {
+	registerCollector("current_issues", true, newCurrentIssuesCollector)
+}
+
+// currentIssuesCollector collects problems detected within the cluster from the rethinkdb.current_issues system table
+type currentIssuesCollector struct {
+	e *RethinkdbExporter
+
+	issues *prometheus.Desc
+}
+
+func newCurrentIssuesCollector(e *RethinkdbExporter) collector {
+	return &currentIssuesCollector{
+		e: e,
+		issues: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "cluster", "issues"),
+			"Number of current issues of the cluster by type",
+			"type", "critical"),
+	}
+}
+
+type currentIssue struct {
+	Type     string `rethinkdb:"type"`
+	Critical bool   `rethinkdb:"critical"`
+}
+
+type issueKey struct {
+	issueType string
+	critical  bool
+}
+
+// Describe sends metrics descriptions to the prometheus chan
+func (c *currentIssuesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.issues
+}
+
+// Update sends collected metrics values to the prometheus chan
+func (c *currentIssuesCollector) Update(ctx context.Context, ch chan<- prometheus.Metric) error {
+	var issues []currentIssue
+	err := c.e.readAll(ctx, r.CurrentIssuesSystemTable, c.e.systemTable(r.CurrentIssuesSystemTable), &issues)
+	if err != nil {
+		return fmt.Errorf("failed to query system current_issues table: %w", err)
+	}
+
+	counts := make(map[issueKey]int)
+	for _, issue := range issues {
+		counts[issueKey{issueType: issue.Type, critical: issue.Critical}]++
+	}
+	for key, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.issues, prometheus.GaugeValue, float64(count), key.issueType, strconv.FormatBool(key.critical))
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	r "gopkg.in/rethinkdb/rethinkdb-go.v6"
+)
+
+func init() {
+	registerCollector("db_config", true, newDBConfigCollector)
+}
+
+// dbConfigCollector counts the databases and tables of the cluster
+// from the rethinkdb.db_config and rethinkdb.table_config system tables
+type dbConfigCollector struct {
+	e *RethinkdbExporter
+
+	databases *prometheus.Desc
+	tables    *prometheus.Desc
+	dbTables  *prometheus.Desc
+}
+
+func newDBConfigCollector(e *RethinkdbExporter) collector {
+	return &dbConfigCollector{
+		e: e,
+		databases: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "cluster", "databases"),
+			"Number of databases in the cluster"),
+		tables: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "cluster", "tables"),
+			"Number of tables in the cluster"),
+		dbTables: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "db", "tables"),
+			"Number of tables in the database",
+			"db"),
+	}
+}
+
+type dbConfig struct {
+	ID   string `rethinkdb:"id"`
+	Name string `rethinkdb:"name"`
+}
+
+// Describe sends metrics descriptions to the prometheus chan
+func (c *dbConfigCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.databases
+	ch <- c.tables
+	ch <- c.dbTables
+}
+
+// Update sends collected metrics values to the prometheus chan.
+// The cluster counts include the databases and tables dropped by the filters, the counts per database don't.
+func (c *dbConfigCollector) Update(ctx context.Context, ch chan<- prometheus.Metric) error {
+	var dbs []dbConfig
+	err := c.e.readAll(ctx, r.DBConfigSystemTable, c.e.systemTable(r.DBConfigSystemTable), &dbs)
+	if err != nil {
+		return fmt.Errorf("failed to query system db_config table: %w", err)
+	}
+	var tables []tableConfig
+	err = c.e.readAll(ctx, r.TableConfigSystemTable, c.e.systemTable(r.TableConfigSystemTable).Pluck("id", "db", "name"), &tables)
+	if err != nil {
+		return fmt.Errorf("failed to query system table_config table: %w", err)
+	}
+
+	counts := make(map[string]int, len(dbs))
+	for _, db := range dbs {
+		counts[db.Name] = 0
+	}
+	for _, table := range tables {
+		counts[table.Database]++
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.databases, prometheus.GaugeValue, float64(len(dbs)))
+	ch <- prometheus.MustNewConstMetric(c.tables, prometheus.GaugeValue, float64(len(tables)))
+	for db, count := range counts {
+		if !c.e.filters.dbs.matches(db) {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.dbTables, prometheus.GaugeValue, float64(count), db)
+	}
+	return nil
+}
@@ -1,96 +1,138 @@
 package exporter
 
 import (
+	"fmt"
+	"regexp"
+
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+const (
+	// defaultNamespace prefixes all metrics except the original stats ones, which are kept unprefixed for compatibility
+	// unless the namespace is set
+	defaultNamespace = "rethinkdb"
+	// exporterSubsystem prefixes metrics of the exporter itself
+	exporterSubsystem = "exporter"
+)
+
 const (
 	readOperation    = "read"
 	writtenOperation = "written"
 )
 
+const (
+	// serverIDLabel is name of the label with the uuid of the server, which is stable across renames of the server
+	serverIDLabel = "server_id"
+	// tableIDLabel is name of the label with the uuid of the table, which differs for a table recreated with the same name
+	tableIDLabel = "table_id"
+	// serverRoleLabel is name of the label with the role of the server, either data or proxy
+	serverRoleLabel = "role"
+)
+
+const (
+	dataRole  = "data"
+	proxyRole = "proxy"
+)
+
 // Describe sends metrics descriptions to the prometheus chan
 func (e *RethinkdbExporter) Describe(ch chan<- *prometheus.Desc) {
-	ch <- e.metrics.clusterClientConnections
-	ch <- e.metrics.clusterDocsPerSecond
+	ch <- e.up
+	for _, c := range e.collectors {
+		c.Describe(ch)
+	}
+	for _, d := range e.legacyDescs {
+		ch <- d
+	}
+	for _, c := range e.clusters {
+		c.Describe(ch)
+	}
+}
 
-	ch <- e.metrics.serverClientConnections
-	ch <- e.metrics.serverQueriesPerSecond
-	ch <- e.metrics.serverDocsPerSecond
+// namespacePattern matches valid prefixes of metric names
+var namespacePattern = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
 
-	ch <- e.metrics.tableDocsPerSecond
-	if e.metrics.tableRowsCount != nil {
-		ch <- e.metrics.tableRowsCount
+// metricsNamespace returns the prefix of metric names according to the options
+func metricsNamespace(opts Options) string {
+	if opts.Namespace == "" {
+		return defaultNamespace
 	}
+	return opts.Namespace
+}
 
-	ch <- e.metrics.tableReplicaDocsPerSecond
-	ch <- e.metrics.tableReplicaCacheBytes
-	ch <- e.metrics.tableReplicaIO
-	ch <- e.metrics.tableReplicaDataBytes
+// validateNamespace checks that the prefix of metric names is valid
+func validateNamespace(namespace string) error {
+	if namespace != "" && !namespacePattern.MatchString(namespace) {
+		return fmt.Errorf("invalid metrics namespace %q", namespace)
+	}
+	return nil
+}
 
-	ch <- e.metrics.scrapeLatency
-	ch <- e.metrics.scrapeErrors
+// newDesc creates metric description with variable labels renamed according to the options
+// and the static labels and the cluster label as constant ones
+func (e *RethinkdbExporter) newDesc(name, help string, labels ...string) *prometheus.Desc {
+	return prometheus.NewDesc(name, help, e.labelNames(labels), e.constLabels)
 }
 
-func (e *RethinkdbExporter) initMetrics() {
-	e.metrics.clusterClientConnections = prometheus.NewDesc(
-		"cluster_client_connections",
-		"Total number of connections from the cluster",
-		nil, nil,
-	)
-	e.metrics.clusterDocsPerSecond = prometheus.NewDesc(
-		"cluster_docs_per_second",
-		"Total number of reads and writes of documents per second from the cluster",
-		[]string{"operation"}, nil)
-
-	e.metrics.serverClientConnections = prometheus.NewDesc(
-		"server_client_connections",
-		"Number of client connections to the server",
-		[]string{"server"}, nil)
-	e.metrics.serverQueriesPerSecond = prometheus.NewDesc(
-		"server_queries_per_second",
-		"Number of queries per second from the server",
-		[]string{"server"}, nil)
-	e.metrics.serverDocsPerSecond = prometheus.NewDesc(
-		"server_docs_per_second",
-		"Total number of reads and writes of documents per second from the server",
-		[]string{"server", "operation"}, nil)
-
-	e.metrics.tableDocsPerSecond = prometheus.NewDesc(
-		"table_docs_per_second",
-		"Number of reads and writes of documents per second from the table",
-		[]string{"db", "table", "operation"}, nil)
-
-	if e.collectTableStats {
-		e.metrics.tableRowsCount = prometheus.NewDesc(
-			"table_rows_count",
-			"Approximate number of rows in the table",
-			[]string{"db", "table"}, nil)
+// newStatsDesc creates description of the original stats metric like newDesc, prefixing its name
+// with the namespace only if it is set.
+// The legacy name of the metric is described as well if it is enabled.
+func (e *RethinkdbExporter) newStatsDesc(name, help string, labels ...string) *prometheus.Desc {
+	renamed := e.labelNames(labels)
+	desc := prometheus.NewDesc(e.statsName(name), help, renamed, e.constLabels)
+	e.addLegacyDesc(desc, name, help, renamed)
+	return desc
+}
+
+// statsName returns the name of the original stats metric prefixed with the namespace if it is set
+func (e *RethinkdbExporter) statsName(name string) string {
+	return prometheus.BuildFQName(e.opts.Namespace, "", name)
+}
+
+// labelNames returns the labels renamed according to the options
+func (e *RethinkdbExporter) labelNames(labels []string) []string {
+	renamed := make([]string, 0, len(labels))
+	for _, label := range labels {
+		renamed = append(renamed, e.labelName(label))
 	}
+	return renamed
+}
 
-	e.metrics.tableReplicaDocsPerSecond = prometheus.NewDesc(
-		"tablereplica_docs_per_second",
-		"Number of reads and writes of documents per second from the table replica",
-		[]string{"db", "table", "server", "operation"}, nil)
-	e.metrics.tableReplicaCacheBytes = prometheus.NewDesc(
-		"tablereplica_cache_bytes",
-		"Table replica cache size in bytes",
-		[]string{"db", "table", "server"}, nil)
-	e.metrics.tableReplicaIO = prometheus.NewDesc(
-		"tablereplica_io",
-		"Table replica reads and writes of bytes per second",
-		[]string{"db", "table", "server", "operation"}, nil)
-	e.metrics.tableReplicaDataBytes = prometheus.NewDesc(
-		"tablereplica_data_bytes",
-		"Table replica size in stored bytes",
-		[]string{"db", "table", "server"}, nil)
-
-	e.metrics.scrapeLatency = prometheus.NewDesc(
-		"scrape_latency",
-		"Latency of collecting scrape",
-		nil, nil)
-	e.metrics.scrapeErrors = prometheus.NewDesc(
-		"scrape_errors",
-		"Number of errors while collecting scrape",
-		nil, nil)
+// withServerID appends the server id label to the labels of a per-server metric if it is enabled
+func (e *RethinkdbExporter) withServerID(labels ...string) []string {
+	if e.opts.ServerIDLabel {
+		labels = append(labels, serverIDLabel)
+	}
+	return labels
+}
+
+// withServerIDValue appends the uuid of the server to the label values of a per-server metric if it is enabled
+func (e *RethinkdbExporter) withServerIDValue(id string, values ...string) []string {
+	if e.opts.ServerIDLabel {
+		values = append(values, id)
+	}
+	return values
+}
+
+// withTableID appends the table id label to the labels of a per-table metric if it is enabled
+func (e *RethinkdbExporter) withTableID(labels ...string) []string {
+	if e.opts.TableIDLabel {
+		labels = append(labels, tableIDLabel)
+	}
+	return labels
+}
+
+// withTableIDValue appends the uuid of the table to the label values of a per-table metric if it is enabled
+func (e *RethinkdbExporter) withTableIDValue(id string, values ...string) []string {
+	if e.opts.TableIDLabel {
+		values = append(values, id)
+	}
+	return values
+}
+
+// labelName returns the name of the label in the exported metrics according to the label renames
+func (e *RethinkdbExporter) labelName(label string) string {
+	if to, ok := e.labelRenames[label]; ok {
+		return to
+	}
+	return label
 }
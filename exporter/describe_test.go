@@ -0,0 +1,158 @@
+package exporter
+
+import (
+	"log/slog"
+	"slices"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestLabelRenames(t *testing.T) {
+	tests := []struct {
+		name       string
+		renames    map[string]string
+		metric     string
+		wantLabels []string
+	}{
+		{
+			name:       "no renames",
+			metric:     "rethinkdb_table_ready_for_writes",
+			wantLabels: []string{"db", "table"},
+		},
+		{
+			name:       "db and table",
+			renames:    map[string]string{"db": "database", "table": "rethinkdb_table"},
+			metric:     "rethinkdb_table_ready_for_writes",
+			wantLabels: []string{"database", "rethinkdb_table"},
+		},
+		{
+			name:       "other labels are kept",
+			renames:    map[string]string{"table": "collection"},
+			metric:     "rethinkdb_db_tables",
+			wantLabels: []string{"db"},
+		},
+		{
+			name:       "server",
+			renames:    map[string]string{"server": "instance"},
+			metric:     "rethinkdb_server_uptime_seconds",
+			wantLabels: []string{"instance"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := Options{LabelRenames: tt.renames}
+			mock, err := NewMockSession(opts, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			c, err := NewCollector(slog.New(slog.DiscardHandler), mock, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			registry := prometheus.NewRegistry()
+			registry.MustRegister(c)
+			families, err := registry.Gather()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			i := slices.IndexFunc(families, func(f *dto.MetricFamily) bool { return f.GetName() == tt.metric })
+			if i < 0 || len(families[i].GetMetric()) == 0 {
+				t.Fatalf("metric %s not collected", tt.metric)
+			}
+			for _, m := range families[i].GetMetric() {
+				var labels []string
+				for _, l := range m.GetLabel() {
+					labels = append(labels, l.GetName())
+				}
+				if !slices.Equal(labels, tt.wantLabels) {
+					t.Errorf("labels of %s = %v, want %v", tt.metric, labels, tt.wantLabels)
+				}
+			}
+		})
+	}
+}
+
+func TestLabelRenamesInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		renames map[string]string
+	}{
+		{name: "empty", renames: map[string]string{"db": ""}},
+		{name: "clashing", renames: map[string]string{"db": "table"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCollector(slog.New(slog.DiscardHandler), nil, Options{LabelRenames: tt.renames})
+			if err == nil {
+				t.Errorf("NewCollector() accepted label renames %v", tt.renames)
+			}
+		})
+	}
+}
+
+func TestNamespace(t *testing.T) {
+	tests := []struct {
+		name        string
+		namespace   string
+		legacyNames string
+		want        []string
+		notWant     []string
+	}{
+		{
+			name:    "default",
+			want:    []string{"cluster_client_connections", "rethinkdb_table_ready_for_writes"},
+			notWant: []string{"rethinkdb_cluster_client_connections"},
+		},
+		{
+			name:      "custom",
+			namespace: "db",
+			want:      []string{"db_cluster_client_connections", "db_table_ready_for_writes"},
+			notWant:   []string{"cluster_client_connections", "rethinkdb_table_ready_for_writes"},
+		},
+		{
+			name:        "legacy names equal to the current ones",
+			namespace:   "rethinkdb",
+			legacyNames: LegacyNamesAdditional,
+			want:        []string{"rethinkdb_cluster_client_connections", "rethinkdb_table_ready_for_writes"},
+			notWant:     []string{"cluster_client_connections"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := Options{Namespace: tt.namespace, LegacyNames: tt.legacyNames}
+			mock, err := NewMockSession(opts, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			c, err := NewCollector(slog.New(slog.DiscardHandler), mock, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			registry := prometheus.NewRegistry()
+			registry.MustRegister(c)
+			families, err := registry.Gather()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			names := make([]string, 0, len(families))
+			for _, f := range families {
+				names = append(names, f.GetName())
+			}
+			for _, name := range tt.want {
+				if !slices.Contains(names, name) {
+					t.Errorf("metric %s not collected", name)
+				}
+			}
+			for _, name := range tt.notWant {
+				if slices.Contains(names, name) {
+					t.Errorf("metric %s collected", name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+	r "gopkg.in/rethinkdb/rethinkdb-go.v6"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Dump collects the metrics once and writes them in the prometheus text format.
+// It returns an error if any collector failed or a cluster couldn't be queried,
+// the successfully collected metrics are written anyway.
+func Dump(ctx context.Context, log *slog.Logger, rconn r.QueryExecutor, opts Options, w io.Writer) error {
+	err := validateOptions(opts)
+	if err != nil {
+		return err
+	}
+	// the single collection doesn't wait for changefeeds
+	opts.StreamStats = false
+
+	e := newCollector(log, rconn, opts)
+	registry := prometheus.NewRegistry()
+	err = registry.Register(requestCollector{RethinkdbExporter: e, ctx: ctx})
+	if err != nil {
+		return fmt.Errorf("failed to register metrics: %w", err)
+	}
+	families, gatherErr := registry.Gather()
+
+	enc := expfmt.NewEncoder(w, expfmt.NewFormat(expfmt.TypeTextPlain))
+	for _, f := range families {
+		err = enc.Encode(f)
+		if err != nil {
+			return fmt.Errorf("failed to write metrics: %w", err)
+		}
+	}
+
+	return errors.Join(gatherErr, e.lastScrapeError())
+}
+
+// lastScrapeError returns an error if any collector failed or a cluster couldn't be queried during the last scrape
+func (e *RethinkdbExporter) lastScrapeError() error {
+	c := e.current()
+	var errs []error
+	if failed := failedCollectors(c.self.clusterSelfMetrics); len(failed) > 0 {
+		errs = append(errs, fmt.Errorf("collectors failed: %s", strings.Join(failed, ", ")))
+	}
+	if c.health.failing() {
+		errs = append(errs, errors.New("cluster could not be queried"))
+	}
+	for _, cluster := range c.clusters {
+		if cluster.self != c.self {
+			if failed := failedCollectors(cluster.self.clusterSelfMetrics); len(failed) > 0 {
+				errs = append(errs, fmt.Errorf("collectors of cluster %s failed: %s", cluster.opts.ClusterName, strings.Join(failed, ", ")))
+			}
+		}
+		if cluster.health.failing() {
+			errs = append(errs, fmt.Errorf("cluster %s could not be queried", cluster.opts.ClusterName))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// failedCollectors returns the sorted names of the collectors and their phases which failed during the last scrape
+func failedCollectors(s *clusterSelfMetrics) []string {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		s.collectorSuccess.Collect(ch)
+		close(ch)
+	}()
+
+	var failed []string
+	for m := range ch {
+		var metric dto.Metric
+		if m.Write(&metric) != nil || metric.GetGauge().GetValue() != 0 {
+			continue
+		}
+		for _, l := range metric.GetLabel() {
+			if l.GetName() == "collector" {
+				failed = append(failed, l.GetValue())
+			}
+		}
+	}
+	slices.Sort(failed)
+	return failed
+}
@@ -0,0 +1,77 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	r "gopkg.in/rethinkdb/rethinkdb-go.v6"
+	"gopkg.in/rethinkdb/rethinkdb-go.v6/encoding"
+)
+
+// types of scrape errors, used as values of the type label of the errors counter
+const (
+	connectionError = "connection"
+	cursorError     = "cursor"
+	decodeError     = "decode"
+	tableInfoError  = "table_info"
+	timeoutError    = "timeout"
+)
+
+var scrapeErrorTypes = []string{connectionError, cursorError, decodeError, tableInfoError, timeoutError}
+
+// tableInfoErr marks errors of the table info queries, which are run for every table separately
+type tableInfoErr struct {
+	err error
+}
+
+func (e tableInfoErr) Error() string {
+	return e.err.Error()
+}
+
+func (e tableInfoErr) Unwrap() error {
+	return e.err
+}
+
+// errorTypes classifies every error combined with errors.Join
+func errorTypes(err error) []string {
+	if err == nil {
+		return nil
+	}
+	var joined interface{ Unwrap() []error }
+	if errors.As(err, &joined) {
+		var types []string
+		for _, err := range joined.Unwrap() {
+			types = append(types, errorTypes(err)...)
+		}
+		return types
+	}
+	return []string{errorType(err)}
+}
+
+// errorType classifies the error, errors of queries are cursor errors unless they are caused by the connection or decoding.
+// Errors of the table info queries are classified as such whatever caused them.
+func errorType(err error) string {
+	var (
+		connErr      r.RQLConnectionError
+		authErr      r.RQLAuthError
+		netErr       net.Error
+		decodeErr    *encoding.DecodeTypeError
+		unexpErr     *encoding.UnexpectedTypeError
+		unmarshalErr *encoding.InvalidUnmarshalError
+		infoErr      tableInfoErr
+	)
+	switch {
+	case errors.As(err, &infoErr):
+		return tableInfoError
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled), errors.Is(err, r.ErrQueryTimeout):
+		return timeoutError
+	case errors.Is(err, r.ErrConnectionClosed), errors.Is(err, r.ErrNoConnections), errors.Is(err, r.ErrNoConnectionsStarted),
+		errors.Is(err, r.ErrNoHosts), errors.As(err, &connErr), errors.As(err, &authErr), errors.As(err, &netErr):
+		return connectionError
+	case errors.As(err, &decodeErr), errors.As(err, &unexpErr), errors.As(err, &unmarshalErr):
+		return decodeError
+	default:
+		return cursorError
+	}
+}
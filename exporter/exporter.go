@@ -1,46 +1,284 @@
 package exporter
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"net"
 	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/coreos/go-systemd/v22/activation"
+	"github.com/coreos/go-systemd/v22/daemon"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
-	"github.com/prometheus/common/version"
+	"github.com/prometheus/exporter-toolkit/web"
 	r "gopkg.in/rethinkdb/rethinkdb-go.v6"
 )
 
+// unixSocketPrefix marks listen address as path of unix socket
+const unixSocketPrefix = "unix://"
+
+// defaultReadHeaderTimeout limits reading of the request headers unless the options set it
+const defaultReadHeaderTimeout = 10 * time.Second
+
 // RethinkdbExporter is a prometheus exporter of the rethinkdb statistics
 type RethinkdbExporter struct {
 	rconn r.QueryExecutor
+	// inUse is held for reading by the collections using the connection,
+	// it is locked once after the connection is replaced by Reconfigure
+	inUse sync.RWMutex
 
+	opts              Options
 	collectTableStats bool
+	labelRenames      map[string]string
+	namespace         string
+	filters           filters
+	constLabels       prometheus.Labels
+	legacyDescs       map[*prometheus.Desc]*prometheus.Desc
+	scrapeTimeout     time.Duration
+	// clusters are collectors of the other clusters scraped together with this one
+	clusters []*RethinkdbExporter
 
 	listenAddress string
+	registerer    prometheus.Registerer
+	metaGatherer  prometheus.Gatherer
 	mux           *http.ServeMux
+	server        *http.Server
+	pprofServer   *http.Server
+	adminServer   *http.Server
+	requestSlots  chan struct{}
+	guard         *scrapeGuard
+
+	log        *slog.Logger
+	self       *selfMetrics
+	collectors map[string]collector
+
+	up      *prometheus.Desc
+	health  scrapeHealth
+	breaker circuitBreaker
+	streams streams
 
-	log     *slog.Logger
-	metrics struct {
-		clusterClientConnections *prometheus.Desc
-		clusterDocsPerSecond     *prometheus.Desc
+	cache          *metricsCache
+	cacheAge       *prometheus.Desc
+	collecting     context.Context
+	stopCollecting context.CancelFunc
 
-		serverClientConnections *prometheus.Desc
-		serverQueriesPerSecond  *prometheus.Desc
-		serverDocsPerSecond     *prometheus.Desc
+	outputs     []scheduledOutput
+	pushing     context.Context
+	stopPushing context.CancelFunc
+	pushLoops   sync.WaitGroup
 
-		tableDocsPerSecond *prometheus.Desc
-		tableRowsCount     *prometheus.Desc
+	active      atomic.Pointer[RethinkdbExporter]
+	reloadMutex sync.Mutex
+}
 
-		tableReplicaDocsPerSecond *prometheus.Desc
-		tableReplicaCacheBytes    *prometheus.Desc
-		tableReplicaIO            *prometheus.Desc
-		tableReplicaDataBytes     *prometheus.Desc
+// Options defines which stats the exporter collects and how they are exported
+type Options struct {
+	// Collectors enables or disables collectors by name, the others are enabled by default or not
+	Collectors map[string]bool
+	// CollectTableStats enables collecting table rows count estimates
+	CollectTableStats bool
+	// CollectShardEstimates enables collecting documents count estimates of every shard besides the table rows count estimates
+	CollectShardEstimates bool
+	// TableInfoConcurrency limits number of concurrent queries of the table rows count estimates
+	// and of the index status of the tables, zero means no limit
+	TableInfoConcurrency int
+	// TableInfoTimeout limits duration of every query of the table rows count estimates and of the index status of the tables,
+	// zero means no limit besides the scrape timeout. Estimates of the tables exceeding it are skipped without failing the scrape.
+	TableInfoTimeout time.Duration
+	// ExactCountTables are tables given as db.table whose rows are counted exactly by the table_rows_exact collector
+	ExactCountTables []string
+	// ExactCountInterval is the interval of counting the rows in the background, an hour if it is zero
+	ExactCountInterval time.Duration
+	// DBFilter limits per-table and per-replica metrics to the databases matching the regular expression if it is not empty
+	DBFilter string
+	// DBExclude drops per-table and per-replica metrics of the databases matching the regular expression if it is not empty
+	DBExclude string
+	// TableFilter limits per-table and per-replica metrics to the tables matching the regular expression if it is not empty
+	TableFilter string
+	// TableExclude drops per-table and per-replica metrics of the tables matching the regular expression if it is not empty
+	TableExclude string
+	// ServerSideFilters applies the database, table and server filters in the stats queries as well,
+	// so the rethinkdb doesn't send stats of the dropped tables and servers
+	ServerSideFilters bool
+	// ServerFilter limits per-server and per-replica metrics to the servers matching the regular expression if it is not empty
+	ServerFilter string
+	// ServerExclude drops per-server and per-replica metrics of the servers matching the regular expression if it is not empty
+	ServerExclude string
+	// ScrapeTimeout limits duration of the stats collection, zero means no limit
+	ScrapeTimeout time.Duration
+	// OutdatedReads runs the queries with the outdated read mode, so they are answered by any replica
+	OutdatedReads bool
+	// QueryRetries is how many times queries are retried after connection errors during a scrape
+	QueryRetries int
+	// QueryRetryBackoff is the wait before the first retry, it doubles with every next one
+	QueryRetryBackoff time.Duration
+	// BreakerThreshold is number of consecutive failed scrapes opening the circuit breaker, zero disables it
+	BreakerThreshold int
+	// BreakerCooldown is how long the open circuit breaker serves scrapes without querying the cluster
+	BreakerCooldown time.Duration
+	// StreamStats keeps the stats and table_status system tables up to date by changefeeds
+	// instead of querying them on every scrape. It is experimental.
+	StreamStats bool
+	// CollectInterval enables collecting in the background on the interval instead of on every scrape,
+	// scrapes are served from the cache of the last collection
+	CollectInterval time.Duration
+	// MaxStaleness limits age of the cached metrics served in the background collection mode, zero means no limit
+	MaxStaleness time.Duration
+	// MinScrapeInterval serves the metrics of the last scrape to the scrapes arriving sooner than the interval after it,
+	// so they don't query the cluster again. It is not used in the background collection mode.
+	MinScrapeInterval time.Duration
+	// RejectFrequentScrapes rejects the scrapes arriving sooner than the min scrape interval with 429
+	// instead of serving them the last metrics
+	RejectFrequentScrapes bool
+	// ReadyGracePeriod is how long scrapes of the cluster may fail before the exporter is reported as not ready
+	ReadyGracePeriod time.Duration
+	// ServerIDLabel adds the server_id label with the uuid of the server to the server and table replica stats
+	// and the server status metrics besides the server name
+	ServerIDLabel bool
+	// ServerRoleLabel adds the role label to the server stats, which is proxy for the servers
+	// missing in the server_config system table and data for the others
+	ServerRoleLabel bool
+	// TableIDLabel adds the table_id label with the uuid of the table to the table and table replica stats
+	// and the table status, config and index metrics besides the db and table names
+	TableIDLabel bool
+	// LabelRenames maps default label names to the names used in exported metrics
+	LabelRenames map[string]string
+	// Namespace prefixes names of the metrics instead of "rethinkdb" if it is not empty.
+	// The original stats metrics are only prefixed if it is not empty.
+	// Changes of it are not applied to the exporter's own metrics by Reconfigure.
+	Namespace string
+	// Labels are static labels added to all exported metrics. Changes of them are not applied
+	// to the exporter's own metrics by Reconfigure.
+	Labels map[string]string
+	// ScrapeDurationBuckets are upper bounds of the scrape duration histogram buckets, the default ones are used if empty
+	ScrapeDurationBuckets []float64
+	// LegacyScrapeLatency enables the scrape_latency gauge replaced by the scrape duration histogram
+	LegacyScrapeLatency bool
+	// LegacyNames exports the stats metrics under the names of the oliver006/rethinkdb_exporter
+	// besides (LegacyNamesAdditional) or instead of (LegacyNamesExclusive) the current ones if it is not empty
+	LegacyNames string
+	// NativeHistograms enables native histograms of the scrape and query durations in addition to the classic ones
+	NativeHistograms bool
+	// EnableOpenMetrics serves the OpenMetrics format to the scrapers requesting it
+	EnableOpenMetrics bool
+	// CreatedSamples adds the _created samples of counters and histograms to the OpenMetrics output
+	CreatedSamples bool
+	// ExpositionFormat forces the format of the served metrics regardless of the Accept header if it is not empty
+	ExpositionFormat string
+	// OTLP pushes the metrics to an OpenTelemetry collector if its endpoint is not empty
+	OTLP OTLPOptions
+	// Pushgateway pushes the metrics to a Pushgateway if its url is not empty
+	Pushgateway PushgatewayOptions
+	// Graphite pushes the metrics to a Carbon endpoint if its address is not empty
+	Graphite GraphiteOptions
+	// StatsD emits the metrics as StatsD or DogStatsD gauges if its address is not empty
+	StatsD StatsDOptions
+	// Textfile writes the metrics for the textfile collector of the node_exporter instead of serving them
+	// if its directory is not empty
+	Textfile TextfileOptions
+	// BearerToken is required in the Authorization header of requests for metrics, probes and reloads if it is not empty
+	BearerToken string
+	// WebConfigFile is path of the exporter-toolkit web config file enabling TLS and basic auth on the http-server
+	WebConfigFile string
+	// SystemdSocket serves the http-server on the socket passed by systemd socket activation instead of the listen address
+	SystemdSocket bool
+	// EnablePprof serves profiles of the exporter under /debug/pprof
+	EnablePprof bool
+	// PprofAddress serves the profiles on a separate http-server listening on the address if it is not empty
+	PprofAddress string
+	// AdminAddress serves the /-/healthy, /-/ready, /-/reload and /-/loglevel paths on a separate http-server
+	// listening on the address instead of the one of the metrics if it is not empty
+	AdminAddress string
+	// AccessLog logs every request served by the http-servers
+	AccessLog bool
+	// MaxRequests limits number of concurrent requests for the rethinkdb metrics, the ones over it are rejected with 503.
+	// Zero means no limit.
+	MaxRequests int
+	// ReadTimeout limits reading of a whole request by the http-servers, zero means no limit
+	ReadTimeout time.Duration
+	// ReadHeaderTimeout limits reading of the request headers by the http-servers, 10s if it is zero
+	ReadHeaderTimeout time.Duration
+	// WriteTimeout limits writing of the response by the http-server of the metrics, zero means no limit.
+	// It should be longer than the scrape timeout so the slowest scrapes are still served.
+	WriteTimeout time.Duration
+	// IdleTimeout limits waiting for the next request on a keep-alive connection, the read timeout is used if it is zero
+	IdleTimeout time.Duration
+	// MaxHeaderBytes limits size of the request headers, 1MB if it is zero
+	MaxHeaderBytes int
+	// LandingPage customizes or disables the page served on the / path
+	LandingPage LandingPageOptions
+	// ExternalURL is the url the http-server is reachable at through a reverse proxy,
+	// the links of the landing page are prefixed with its path
+	ExternalURL string
+	// TLSConfig enables TLS on the http-server, it can't be combined with WebConfigFile
+	TLSConfig *tls.Config
+	// MetaTelemetryPath is http url path for the exporter's own metrics.
+	// They are served together with the rethinkdb metrics if it is empty.
+	MetaTelemetryPath string
+	// DisableExporterMetrics drops the Go runtime and process metrics of the private registry
+	// and the promhttp metrics of the http handlers
+	DisableExporterMetrics bool
+	// Registerer registers the exporter's own metrics, they are served if it is a prometheus.Gatherer as well.
+	// A private registry with the Go runtime and process metrics is used if it is nil.
+	Registerer prometheus.Registerer
+	// ClientCertificate is the client certificate of the rethinkdb connection, its expiry is exported if it is not nil
+	ClientCertificate *x509.Certificate
+	// Clusters are connections to other clusters scraped together with the main one by cluster name.
+	// Their metrics and the metrics of the main cluster are labeled with the cluster label.
+	Clusters map[string]r.QueryExecutor
+	// ClusterName is value of the cluster label of the main cluster's metrics, the label is not added if it is empty
+	ClusterName string
+	// ClusterLabel is name of the cluster label, "cluster" if it is empty
+	ClusterLabel string
+	// ProbeModules defines connection parameters of the targets probed on the /probe path by module name,
+	// the path serves 404 if there are none
+	ProbeModules map[string]ProbeModule
+	// OnReload reloads the configuration on a request to the /-/reload path, usually calling Reconfigure.
+	// The path is not served if it is nil.
+	OnReload func() error
+	// LogLevel is the level of the logger, it can be changed on the /-/loglevel path.
+	// The path is not served if it is nil.
+	LogLevel *slog.LevelVar
+}
 
-		scrapeLatency *prometheus.Desc
-		scrapeErrors  *prometheus.Desc
+// newCollector creates the exporter without the http-server part
+func newCollector(log *slog.Logger, rconn r.QueryExecutor, opts Options) *RethinkdbExporter {
+	e := &RethinkdbExporter{
+		opts:              opts,
+		collectTableStats: opts.CollectTableStats,
+		labelRenames:      opts.LabelRenames,
+		namespace:         metricsNamespace(opts),
+		scrapeTimeout:     opts.ScrapeTimeout,
+		rconn:             rconn,
+		log:               log,
+		self:              newSelfMetrics(opts),
 	}
+	// the filters are validated before the collector is created
+	e.filters, _ = newFilters(opts)
+	e.constLabels = maps.Clone(opts.Labels)
+	if opts.ClusterName != "" {
+		if e.constLabels == nil {
+			e.constLabels = prometheus.Labels{}
+		}
+		e.constLabels[clusterLabel(opts)] = opts.ClusterName
+	}
+	e.up = e.newDesc(
+		prometheus.BuildFQName(e.namespace, "", "up"),
+		"Whether the cluster could be queried during the scrape")
+	e.initCollectors()
+	e.initClusters()
+	return e
 }
 
 type promHTTPLogger struct {
@@ -51,62 +289,431 @@ func (l promHTTPLogger) Println(v ...interface{}) {
 	l.log.Error("promhttp", "msg", fmt.Sprint(v...))
 }
 
-// New creates a new instance of prometheus rethinkdb exporter
+// Collector collects the rethinkdb metrics into a prometheus registry without serving them,
+// so the exporter can be embedded into other binaries. The exporter's own metrics are not included.
+// Collectors of several clusters registered together need distinct Labels or ClusterName.
+type Collector struct {
+	e *RethinkdbExporter
+}
+
+// NewCollector creates the collector of the rethinkdb metrics queried on every collection.
+// The options of serving and pushing the metrics are ignored.
+func NewCollector(log *slog.Logger, rconn r.QueryExecutor, opts Options) (*Collector, error) {
+	err := validateOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	e := newCollector(log, rconn, opts)
+	// the metrics are registered on every scrape, so descriptors are validated in advance
+	err = prometheus.NewRegistry().Register(e)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register metrics: %w", err)
+	}
+	return &Collector{e: e}, nil
+}
+
+// Describe sends metrics descriptions to the prometheus chan
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.e.Describe(ch)
+}
+
+// Collect queries the rethinkdb and sends the metrics values to the prometheus chan
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.e.Collect(ch)
+}
+
+// Close stops the changefeeds of the streamed stats, the connection is not closed
+func (c *Collector) Close() error {
+	c.e.stopStreams()
+	return nil
+}
+
+// New creates a new instance of prometheus rethinkdb exporter serving the metrics of the collector
 func New(
 	log *slog.Logger,
 	listenAddress string,
 	telemetryPath string,
 	rconn r.QueryExecutor,
-	collectTableStats bool,
+	opts Options,
 ) (*RethinkdbExporter, error) {
-	exporter := &RethinkdbExporter{
-		listenAddress:     listenAddress,
-		collectTableStats: collectTableStats,
-		rconn:             rconn,
-		log:               log,
+	c, err := NewCollector(log, rconn, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs, err := newOutputs(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	exporter := c.e
+	exporter.listenAddress = listenAddress
+	exporter.outputs = outputs
+	exporter.pushing, exporter.stopPushing = context.WithCancel(context.Background())
+	if opts.CollectInterval > 0 {
+		exporter.enableCache()
+		exporter.collecting, exporter.stopCollecting = context.WithCancel(context.Background())
+	}
+
+	exporter.registerer = opts.Registerer
+	if exporter.registerer == nil {
+		registry := prometheus.NewRegistry()
+		if !opts.DisableExporterMetrics {
+			registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+		}
+		exporter.registerer = registry
 	}
+	exporter.metaGatherer, _ = exporter.registerer.(prometheus.Gatherer)
 
-	exporter.initMetrics()
+	registerer := prometheus.WrapRegistererWith(opts.Labels, exporter.registerer)
+	err = registerer.Register(exporter.self)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register exporter metrics: %w", err)
+	}
+	err = registerer.Register(clustersSelfCollector{exporter})
+	if err != nil {
+		return nil, fmt.Errorf("failed to register exporter metrics of the clusters: %w", err)
+	}
+	err = registerer.Register(newPoolCollector(exporter))
+	if err != nil {
+		return nil, fmt.Errorf("failed to register pool metrics: %w", err)
+	}
+
+	landingPage, err := exporter.landingPage(telemetryPath)
+	if err != nil {
+		return nil, err
+	}
 
-	prometheus.MustRegister(exporter)
+	if opts.MaxRequests > 0 {
+		exporter.requestSlots = make(chan struct{}, opts.MaxRequests)
+	}
+	if opts.MinScrapeInterval > 0 && opts.CollectInterval == 0 {
+		exporter.guard = &scrapeGuard{}
+	}
 
 	exporter.mux = http.NewServeMux()
-	exporter.mux.Handle(telemetryPath,
-		promhttp.InstrumentMetricHandler(
-			prometheus.DefaultRegisterer,
-			promhttp.HandlerFor(
-				prometheus.DefaultGatherer,
-				promhttp.HandlerOpts{
-					ErrorLog: &promHTTPLogger{log: log},
-				},
-			),
-		),
+	exporter.mux.Handle(telemetryPath, exporter.requireToken(exporter.limitRequests(exporter.rejectFrequentScrapes(
+		exporter.rethinkdbHandler(opts.MetaTelemetryPath == "")))))
+	if opts.MetaTelemetryPath != "" && exporter.metaGatherer != nil {
+		exporter.mux.Handle(opts.MetaTelemetryPath, exporter.requireToken(exporter.metricsHandler(exporter.metaGatherer)))
+	}
+	exporter.mux.Handle("/api/v1/stats", exporter.requireToken(http.HandlerFunc(exporter.apiStatsHandler)))
+	exporter.mux.Handle("/probe", exporter.requireToken(http.HandlerFunc(exporter.probeHandler)))
+	exporter.mux.Handle("/", landingPage)
+	exporter.initAdmin()
+	exporter.initPprof()
+
+	var handler http.Handler = exporter.mux
+	if opts.AccessLog {
+		handler = exporter.accessLog(handler)
+	}
+	exporter.server = exporter.newServer(listenAddress, handler)
+
+	return exporter, nil
+}
+
+// newServer creates a http-server limited by the timeouts of the options
+func (e *RethinkdbExporter) newServer(address string, handler http.Handler) *http.Server {
+	readHeaderTimeout := e.opts.ReadHeaderTimeout
+	if readHeaderTimeout == 0 {
+		readHeaderTimeout = defaultReadHeaderTimeout
+	}
+	return &http.Server{
+		Addr:              address,
+		Handler:           handler,
+		ReadTimeout:       e.opts.ReadTimeout,
+		ReadHeaderTimeout: readHeaderTimeout,
+		WriteTimeout:      e.opts.WriteTimeout,
+		IdleTimeout:       e.opts.IdleTimeout,
+		MaxHeaderBytes:    e.opts.MaxHeaderBytes,
+	}
+}
+
+// validateOptions checks the options before the exporter is created
+func validateOptions(opts Options) error {
+	err := validateCollectors(opts.Collectors)
+	if err != nil {
+		return err
+	}
+	err = validateBuckets(opts.ScrapeDurationBuckets)
+	if err != nil {
+		return err
+	}
+	err = validateNamespace(opts.Namespace)
+	if err != nil {
+		return err
+	}
+	err = validateLegacyNames(opts.LegacyNames)
+	if err != nil {
+		return err
+	}
+	err = validateFilters(opts)
+	if err != nil {
+		return err
+	}
+	err = validateExactCountTables(opts.ExactCountTables)
+	if err != nil {
+		return err
+	}
+	err = validateLabels(opts)
+	if err != nil {
+		return err
+	}
+	err = validateProbeModules(opts.ProbeModules)
+	if err != nil {
+		return err
+	}
+	err = validateExpositionFormat(opts.ExpositionFormat)
+	if err != nil {
+		return err
+	}
+	err = validateExternalURL(opts.ExternalURL)
+	if err != nil {
+		return err
+	}
+	if opts.ReadTimeout < 0 || opts.ReadHeaderTimeout < 0 || opts.WriteTimeout < 0 || opts.IdleTimeout < 0 {
+		return errors.New("http server timeouts can't be negative")
+	}
+	if opts.MinScrapeInterval < 0 {
+		return errors.New("min scrape interval can't be negative")
+	}
+	if opts.MaxRequests < 0 {
+		return errors.New("max requests can't be negative")
+	}
+	if opts.MaxHeaderBytes < 0 {
+		return errors.New("http server max header bytes can't be negative")
+	}
+	if opts.WebConfigFile != "" && opts.TLSConfig != nil {
+		return errors.New("web config file and tls config can't be used together")
+	}
+	if opts.WebConfigFile != "" {
+		err = web.Validate(opts.WebConfigFile)
+		if err != nil {
+			return fmt.Errorf("invalid web config file: %w", err)
+		}
+	}
+	return nil
+}
+
+func (e *RethinkdbExporter) metricsHandler(gatherer prometheus.Gatherer) http.Handler {
+	return e.instrumentMetricHandler(e.gathererHandler(gatherer))
+}
+
+// instrumentMetricHandler counts the requests of the handler by the promhttp metrics unless they are disabled
+func (e *RethinkdbExporter) instrumentMetricHandler(handler http.Handler) http.Handler {
+	if e.opts.DisableExporterMetrics {
+		return handler
+	}
+	return promhttp.InstrumentMetricHandler(e.registerer, handler)
+}
+
+// gathererHandler serves the gathered metrics in the format negotiated by the Accept header
+// unless the exposition format is forced by the options
+func (e *RethinkdbExporter) gathererHandler(gatherer prometheus.Gatherer) http.Handler {
+	handler := promhttp.HandlerFor(
+		gatherer,
+		promhttp.HandlerOpts{
+			ErrorLog:                            &promHTTPLogger{log: e.log},
+			EnableOpenMetrics:                   e.opts.EnableOpenMetrics || e.opts.ExpositionFormat == OpenMetricsFormat,
+			EnableOpenMetricsTextCreatedSamples: e.opts.CreatedSamples,
+		},
 	)
-	exporter.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		_, _ = w.Write([]byte(`<html>
-             <head><title>RethinkDB Exporter</title></head>
-             <body>
-             <h1>RethinkDB Exporter</h1>
-             <p><a href='` + telemetryPath + `'>Metrics</a></p>
-             <h2>Build</h2>
-             <pre>` + version.Info() + ` ` + version.BuildContext() + `</pre>
-             </body>
-             </html>`))
-	})
-	exporter.mux.HandleFunc("/-/healthy", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		_, _ = fmt.Fprintf(w, "OK")
+	accept, ok := expositionFormats[e.opts.ExpositionFormat]
+	if !ok {
+		return handler
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Set("Accept", accept)
+		handler.ServeHTTP(w, r)
 	})
-	exporter.mux.HandleFunc("/-/ready", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		_, _ = fmt.Fprintf(w, "OK")
+}
+
+// formats of the metrics exposition which can be forced by the options
+const (
+	TextFormat        = "text"
+	OpenMetricsFormat = "openmetrics"
+	ProtobufFormat    = "protobuf"
+)
+
+// expositionFormats maps the forced formats to the Accept headers negotiating them
+var expositionFormats = map[string]string{
+	TextFormat:        "text/plain;version=0.0.4",
+	OpenMetricsFormat: "application/openmetrics-text;version=1.0.0",
+	ProtobufFormat:    "application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited",
+}
+
+// validateExpositionFormat checks that the forced format is known
+func validateExpositionFormat(format string) error {
+	if _, ok := expositionFormats[format]; format != "" && !ok {
+		return fmt.Errorf("unknown exposition format %q, expected %q, %q or %q", format, TextFormat, OpenMetricsFormat, ProtobufFormat)
+	}
+	return nil
+}
+
+// rethinkdbHandler serves the rethinkdb metrics collected within the context of every request.
+// The exporter's own metrics are gathered afterwards if withMeta is set, so they describe the current scrape.
+func (e *RethinkdbExporter) rethinkdbHandler(withMeta bool) http.Handler {
+	return e.instrumentMetricHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gatherer, err := e.gatherer(r.Context(), withMeta)
+		if err != nil {
+			e.log.Error("failed to register metrics", "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		e.gathererHandler(gatherer).ServeHTTP(w, r)
+	}))
+}
+
+// limitRequests rejects the requests over the limit of concurrent requests with 503,
+// so concurrent scrapes by several servers don't multiply the load of the cluster
+func (e *RethinkdbExporter) limitRequests(next http.Handler) http.Handler {
+	if e.requestSlots == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case e.requestSlots <- struct{}{}:
+			defer func() { <-e.requestSlots }()
+		default:
+			e.self.scrapesRejected.WithLabelValues(rejectedMaxRequests).Inc()
+			http.Error(w, fmt.Sprintf("Limit of concurrent requests reached (%d), try again later.", cap(e.requestSlots)),
+				http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r)
 	})
+}
 
-	return exporter, nil
+// gatherer gathers the rethinkdb metrics collected within the context or served from the cache,
+// followed by the exporter's own metrics if withMeta is set
+func (e *RethinkdbExporter) gatherer(ctx context.Context, withMeta bool) (prometheus.Gatherer, error) {
+	var c prometheus.Collector = requestCollector{RethinkdbExporter: e.current(), ctx: ctx}
+	if e.cache != nil {
+		c = cachedCollector{RethinkdbExporter: e}
+	} else if e.guard != nil && !e.opts.RejectFrequentScrapes {
+		c = guardedCollector{RethinkdbExporter: e, ctx: ctx}
+	}
+
+	registry := prometheus.NewRegistry()
+	err := registry.Register(c)
+	if err != nil {
+		return nil, err
+	}
+
+	gatherers := prometheus.Gatherers{registry}
+	if withMeta && e.metaGatherer != nil {
+		gatherers = append(gatherers, e.metaGatherer)
+	}
+	return gatherers, nil
 }
 
-// ListenAndServe runs prometheus http-server for exporting stats
+// ListenAndServe runs prometheus http-server for exporting stats,
+// the background collection and pushing to the outputs if they are enabled.
+// The http-server is not started in the textfile mode.
+// It returns nil after the server is stopped with Shutdown
 func (e *RethinkdbExporter) ListenAndServe() error {
-	serv := http.Server{Addr: e.listenAddress, Handler: e.mux, ReadHeaderTimeout: 10 * time.Second}
-	return serv.ListenAndServe()
+	if e.collecting != nil {
+		go e.collectLoop(e.collecting)
+	}
+	for _, o := range e.outputs {
+		e.pushLoops.Add(1)
+		go func() {
+			defer e.pushLoops.Done()
+			e.pushLoop(e.pushing, o)
+		}()
+	}
+	if e.pprofServer != nil {
+		go e.listenAndServePprof()
+	}
+	if e.adminServer != nil {
+		go e.listenAndServeAdmin()
+	}
+	if e.opts.Textfile.Dir != "" {
+		return e.waitForShutdown()
+	}
+
+	listener, err := e.listen()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = listener.Close() }()
+	if e.opts.TLSConfig != nil {
+		listener = tls.NewListener(listener, e.opts.TLSConfig)
+	}
+
+	// notifies systemd if the exporter is run as a notify service
+	_, err = daemon.SdNotify(false, daemon.SdNotifyReady)
+	if err != nil {
+		e.log.Warn("failed to notify systemd", "error", err)
+	}
+
+	err = web.Serve(listener, e.server, &web.FlagConfig{WebConfigFile: &e.opts.WebConfigFile}, e.log)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// waitForShutdown blocks without serving until Shutdown is called
+func (e *RethinkdbExporter) waitForShutdown() error {
+	_, err := daemon.SdNotify(false, daemon.SdNotifyReady)
+	if err != nil {
+		e.log.Warn("failed to notify systemd", "error", err)
+	}
+	<-e.pushing.Done()
+	return nil
+}
+
+// listen opens the listener on the listen address, which is either tcp address or unix socket path prefixed with unix://,
+// or takes the listener passed by systemd socket activation
+func (e *RethinkdbExporter) listen() (net.Listener, error) {
+	if e.opts.SystemdSocket {
+		listeners, err := activation.Listeners()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get systemd socket: %w", err)
+		}
+		if len(listeners) != 1 {
+			return nil, fmt.Errorf("expected exactly one systemd socket, got %d", len(listeners))
+		}
+		return listeners[0], nil
+	}
+
+	path, ok := strings.CutPrefix(e.listenAddress, unixSocketPrefix)
+	if !ok {
+		return net.Listen("tcp", e.listenAddress)
+	}
+
+	// the socket file may be left over if the exporter was killed
+	info, err := os.Stat(path)
+	if err == nil && info.Mode()&os.ModeSocket != 0 {
+		err = os.Remove(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket file: %w", err)
+		}
+	}
+	return net.Listen("unix", path)
+}
+
+// Shutdown stops the http-server gracefully, waiting for in-flight scrapes until the context is done.
+// The metrics are collected and pushed to the outputs one last time before, so the last interval isn't lost.
+func (e *RethinkdbExporter) Shutdown(ctx context.Context) error {
+	_, err := daemon.SdNotify(false, daemon.SdNotifyStopping)
+	if err != nil {
+		e.log.Warn("failed to notify systemd", "error", err)
+	}
+	if e.stopCollecting != nil {
+		e.stopCollecting()
+	}
+	e.stopPushing()
+	e.pushLoops.Wait()
+	e.pushFinal(ctx)
+	e.current().stopStreams()
+	if e.pprofServer != nil {
+		_ = e.pprofServer.Close()
+	}
+	if e.adminServer != nil {
+		_ = e.adminServer.Shutdown(ctx)
+	}
+	return e.server.Shutdown(ctx)
 }
@@ -0,0 +1,99 @@
+package exporter
+
+import (
+	"fmt"
+	"regexp"
+
+	r "gopkg.in/rethinkdb/rethinkdb-go.v6"
+)
+
+// nameFilter matches names included by one regular expression and not excluded by the other one,
+// both have to match the whole name
+type nameFilter struct {
+	include *regexp.Regexp
+	exclude *regexp.Regexp
+}
+
+func newNameFilter(kind, include, exclude string) (nameFilter, error) {
+	var (
+		f   nameFilter
+		err error
+	)
+	if include != "" {
+		f.include, err = regexp.Compile("^(?:" + include + ")$")
+		if err != nil {
+			return nameFilter{}, fmt.Errorf("invalid %s filter: %w", kind, err)
+		}
+	}
+	if exclude != "" {
+		f.exclude, err = regexp.Compile("^(?:" + exclude + ")$")
+		if err != nil {
+			return nameFilter{}, fmt.Errorf("invalid %s exclude: %w", kind, err)
+		}
+	}
+	return f, nil
+}
+
+func (f nameFilter) matches(name string) bool {
+	if f.include != nil && !f.include.MatchString(name) {
+		return false
+	}
+	return f.exclude == nil || !f.exclude.MatchString(name)
+}
+
+// conditions returns the conditions of the filter on the field evaluated by the rethinkdb,
+// its regular expressions are compatible with the re2 syntax used there
+func (f nameFilter) conditions(field r.Term) []r.Term {
+	var conditions []r.Term
+	if f.include != nil {
+		conditions = append(conditions, field.Match(f.include.String()).Ne(nil))
+	}
+	if f.exclude != nil {
+		conditions = append(conditions, field.Match(f.exclude.String()).Eq(nil))
+	}
+	return conditions
+}
+
+// filters limit the databases, tables and servers whose metrics are exported
+type filters struct {
+	dbs     nameFilter
+	tables  nameFilter
+	servers nameFilter
+}
+
+// newFilters compiles the filters of the options
+func newFilters(opts Options) (filters, error) {
+	var (
+		f   filters
+		err error
+	)
+	f.dbs, err = newNameFilter("db", opts.DBFilter, opts.DBExclude)
+	if err != nil {
+		return filters{}, err
+	}
+	f.tables, err = newNameFilter("table", opts.TableFilter, opts.TableExclude)
+	if err != nil {
+		return filters{}, err
+	}
+	f.servers, err = newNameFilter("server", opts.ServerFilter, opts.ServerExclude)
+	if err != nil {
+		return filters{}, err
+	}
+	return f, nil
+}
+
+// validateFilters checks that the filters of the options are valid regular expressions
+func validateFilters(opts Options) error {
+	_, err := newFilters(opts)
+	return err
+}
+
+// tableMatches returns whether per-table metrics of the table are exported
+func (f filters) tableMatches(db, table string) bool {
+	return f.dbs.matches(db) && f.tables.matches(table)
+}
+
+// serverMatches returns whether per-server metrics of the server are exported
+func (f filters) serverMatches(server string) bool {
+	return f.servers.matches(server)
+}
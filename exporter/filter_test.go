@@ -0,0 +1,136 @@
+package exporter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNameFilterMatches(t *testing.T) {
+	tests := []struct {
+		name    string
+		include string
+		exclude string
+		matches map[string]bool
+	}{
+		{
+			name:    "no filter",
+			matches: map[string]bool{"app": true, "": true},
+		},
+		{
+			name:    "include",
+			include: "app|billing",
+			matches: map[string]bool{"app": true, "billing": true, "apps": false, "test_app": false},
+		},
+		{
+			name:    "exclude",
+			exclude: "test_.*",
+			matches: map[string]bool{"app": true, "test_app": false, "app_test_": true},
+		},
+		{
+			name:    "include and exclude",
+			include: "app.*",
+			exclude: "app_tmp",
+			matches: map[string]bool{"app": true, "app_events": true, "app_tmp": false, "billing": false},
+		},
+		{
+			name:    "whole name",
+			include: "a",
+			matches: map[string]bool{"a": true, "ab": false, "ba": false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := newNameFilter("db", tt.include, tt.exclude)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, want := range tt.matches {
+				if got := f.matches(name); got != want {
+					t.Errorf("matches(%q) = %v, want %v", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewNameFilterInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		include string
+		exclude string
+		wantErr string
+	}{
+		{name: "include", include: "app(", wantErr: "invalid table filter"},
+		{name: "exclude", exclude: "[tmp", wantErr: "invalid table exclude"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newNameFilter("table", tt.include, tt.exclude)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("newNameFilter() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFilters(t *testing.T) {
+	f, err := newFilters(Options{
+		DBFilter:      "app|billing",
+		TableExclude:  "tmp_.*",
+		ServerExclude: "proxy.*",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tables := []struct {
+		db    string
+		table string
+		want  bool
+	}{
+		{db: "app", table: "events", want: true},
+		{db: "billing", table: "invoices", want: true},
+		{db: "app", table: "tmp_import", want: false},
+		{db: "analytics", table: "events", want: false},
+	}
+	for _, tt := range tables {
+		if got := f.tableMatches(tt.db, tt.table); got != tt.want {
+			t.Errorf("tableMatches(%q, %q) = %v, want %v", tt.db, tt.table, got, tt.want)
+		}
+	}
+
+	servers := []struct {
+		server string
+		want   bool
+	}{
+		{server: "db1", want: true},
+		{server: "proxy1", want: false},
+	}
+	for _, tt := range servers {
+		if got := f.serverMatches(tt.server); got != tt.want {
+			t.Errorf("serverMatches(%q) = %v, want %v", tt.server, got, tt.want)
+		}
+	}
+}
+
+func TestValidateFilters(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{name: "none", opts: Options{}},
+		{name: "valid", opts: Options{DBFilter: "app", TableFilter: "events|users", ServerExclude: "proxy.*"}},
+		{name: "invalid db", opts: Options{DBExclude: "(app"}, wantErr: true},
+		{name: "invalid table", opts: Options{TableFilter: "*"}, wantErr: true},
+		{name: "invalid server", opts: Options{ServerFilter: "db["}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateFilters(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateFilters() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
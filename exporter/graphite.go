@@ -0,0 +1,66 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/graphite"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// GraphiteOptions defines pushing of the metrics to a Carbon endpoint of Graphite
+type GraphiteOptions struct {
+	// Address of the Carbon plaintext endpoint given as host:port, the metrics are not pushed if it is empty
+	Address string
+	// Prefix is prepended to the names of the pushed metrics
+	Prefix string
+	// UseTags pushes the labels as Graphite tags instead of appending them to the names
+	UseTags bool
+	// Interval of pushing the metrics
+	Interval time.Duration
+}
+
+// graphiteOutput pushes the metrics with the graphite bridge of the prometheus client
+type graphiteOutput struct {
+	opts GraphiteOptions
+}
+
+func newGraphiteOutput(opts GraphiteOptions) (*graphiteOutput, error) {
+	_, _, err := net.SplitHostPort(opts.Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid graphite address: %w", err)
+	}
+	if opts.Interval <= 0 {
+		return nil, fmt.Errorf("graphite push interval must be positive, got %s", opts.Interval)
+	}
+	return &graphiteOutput{opts: opts}, nil
+}
+
+func (o *graphiteOutput) push(ctx context.Context, families []*dto.MetricFamily) error {
+	timeout := o.opts.Interval
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	bridge, err := graphite.NewBridge(&graphite.Config{
+		URL:     o.opts.Address,
+		Prefix:  o.opts.Prefix,
+		UseTags: o.opts.UseTags,
+		Timeout: timeout,
+		Gatherer: prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+			return families, nil
+		}),
+		ErrorHandling: graphite.AbortOnError,
+	})
+	if err != nil {
+		return err
+	}
+	err = bridge.Push()
+	if err != nil {
+		return fmt.Errorf("failed to push to graphite: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// scrapeGuard remembers the last scrape querying the rethinkdb,
+// so the scrapes arriving sooner than the min scrape interval after it don't query it again
+type scrapeGuard struct {
+	m       sync.Mutex
+	started time.Time
+	last    metricsCache
+}
+
+// tryStart starts a scrape if the interval elapsed since the last one was started,
+// otherwise it returns the time left until the next one may start
+func (g *scrapeGuard) tryStart(interval time.Duration) time.Duration {
+	g.m.Lock()
+	defer g.m.Unlock()
+	now := time.Now()
+	if wait := g.started.Add(interval).Sub(now); wait > 0 {
+		return wait
+	}
+	g.started = now
+	return 0
+}
+
+// guardedCollector collects the metrics if the min scrape interval elapsed since the last scrape,
+// otherwise it serves the metrics of the last scrape
+type guardedCollector struct {
+	*RethinkdbExporter
+	ctx context.Context
+}
+
+// Describe sends metrics descriptions to the prometheus chan
+func (c guardedCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.current().Describe(ch)
+}
+
+// Collect sends the collected or the last metrics values to the prometheus chan
+func (c guardedCollector) Collect(ch chan<- prometheus.Metric) {
+	if c.guard.tryStart(c.opts.MinScrapeInterval) > 0 {
+		metrics, updated := c.guard.last.get()
+		if !updated.IsZero() {
+			c.log.Debug("scrape within the min scrape interval is served from the last scrape")
+			for _, m := range metrics {
+				ch <- m
+			}
+			return
+		}
+	}
+
+	collected := make(chan prometheus.Metric)
+	go func() {
+		current, release := c.acquire()
+		defer release()
+		current.collect(c.ctx, collected)
+		close(collected)
+	}()
+	var metrics []prometheus.Metric
+	for m := range collected {
+		metrics = append(metrics, m)
+		ch <- m
+	}
+	if c.ctx.Err() != nil {
+		return
+	}
+	c.guard.last.set(metrics)
+}
+
+// rejectFrequentScrapes rejects the requests arriving sooner than the min scrape interval after the last one with 429
+func (e *RethinkdbExporter) rejectFrequentScrapes(next http.Handler) http.Handler {
+	if e.guard == nil || !e.opts.RejectFrequentScrapes {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wait := e.guard.tryStart(e.opts.MinScrapeInterval)
+		if wait > 0 {
+			e.self.scrapesRejected.WithLabelValues(rejectedMinInterval).Inc()
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			http.Error(w, fmt.Sprintf("Scrapes are limited to one per %s, try again later.", e.opts.MinScrapeInterval),
+				http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,56 @@
+package exporter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScrapeGuardTryStart(t *testing.T) {
+	tests := []struct {
+		name        string
+		lastStarted time.Duration
+		interval    time.Duration
+		wantStarted bool
+	}{
+		{name: "first scrape", interval: time.Minute, wantStarted: true},
+		{name: "no min interval", lastStarted: time.Millisecond, interval: 0, wantStarted: true},
+		{name: "too soon", lastStarted: 10 * time.Second, interval: time.Minute, wantStarted: false},
+		{name: "interval elapsed", lastStarted: 2 * time.Minute, interval: time.Minute, wantStarted: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &scrapeGuard{}
+			if tt.lastStarted > 0 {
+				g.started = time.Now().Add(-tt.lastStarted)
+			}
+			previous := g.started
+
+			wait := g.tryStart(tt.interval)
+			if started := wait == 0; started != tt.wantStarted {
+				t.Fatalf("tryStart() = %s, want started %v", wait, tt.wantStarted)
+			}
+			if tt.wantStarted {
+				if !g.started.After(previous) {
+					t.Errorf("started scrape wasn't remembered")
+				}
+				return
+			}
+			if want := tt.interval - tt.lastStarted; wait > want || wait < want-time.Second {
+				t.Errorf("tryStart() = %s, want about %s", wait, want)
+			}
+			if !g.started.Equal(previous) {
+				t.Errorf("rejected scrape replaced the last one")
+			}
+		})
+	}
+}
+
+func TestScrapeGuardConsecutive(t *testing.T) {
+	g := &scrapeGuard{}
+	if wait := g.tryStart(time.Hour); wait != 0 {
+		t.Fatalf("first tryStart() = %s, want 0", wait)
+	}
+	if wait := g.tryStart(time.Hour); wait <= 0 {
+		t.Fatalf("second tryStart() = %s, want to wait", wait)
+	}
+}
@@ -0,0 +1,94 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/errgroup"
+	r "gopkg.in/rethinkdb/rethinkdb-go.v6"
+)
+
+func init() {
+	registerCollector("index_status", false, newIndexStatusCollector)
+}
+
+// indexStatusCollector collects secondary indexes of every table with their readiness.
+// It runs a query per table, so it is disabled by default. The queries are limited
+// by the concurrency and timeout of the table info queries.
+type indexStatusCollector struct {
+	e *RethinkdbExporter
+
+	secondaryIndexes *prometheus.Desc
+	indexReady       *prometheus.Desc
+}
+
+func newIndexStatusCollector(e *RethinkdbExporter) collector {
+	return &indexStatusCollector{
+		e: e,
+		secondaryIndexes: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "table", "secondary_indexes"),
+			"Number of secondary indexes of the table",
+			e.withTableID("db", "table")...),
+		indexReady: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "table", "index_ready"),
+			"Whether the secondary index of the table is ready",
+			e.withTableID("db", "table", "index")...),
+	}
+}
+
+type indexStatus struct {
+	Index string `rethinkdb:"index"`
+	Ready bool   `rethinkdb:"ready"`
+}
+
+// Describe sends metrics descriptions to the prometheus chan
+func (c *indexStatusCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.secondaryIndexes
+	ch <- c.indexReady
+}
+
+// Update sends collected metrics values to the prometheus chan
+func (c *indexStatusCollector) Update(ctx context.Context, ch chan<- prometheus.Metric) error {
+	var tables []tableConfig
+	err := c.e.readAll(ctx, r.TableConfigSystemTable, c.e.systemTable(r.TableConfigSystemTable).Pluck("id", "db", "name"), &tables)
+	if err != nil {
+		return fmt.Errorf("failed to query system table_config table: %w", err)
+	}
+
+	wg := &errgroup.Group{}
+	if c.e.opts.TableInfoConcurrency > 0 {
+		wg.SetLimit(c.e.opts.TableInfoConcurrency)
+	}
+	for _, table := range tables {
+		dbName := table.Database
+		tableName := table.Name
+		tableID := table.ID
+		if !c.e.filters.tableMatches(dbName, tableName) {
+			continue
+		}
+
+		wg.Go(func() error {
+			queryCtx := ctx
+			if c.e.opts.TableInfoTimeout > 0 {
+				var cancel context.CancelFunc
+				queryCtx, cancel = context.WithTimeout(ctx, c.e.opts.TableInfoTimeout)
+				defer cancel()
+			}
+
+			var statuses []indexStatus
+			err := c.e.readAll(queryCtx, "index_status", r.DB(dbName).Table(tableName).IndexStatus(), &statuses)
+			if err != nil {
+				c.e.log.Warn("failed to get index status", "db", dbName, "table", tableName, "error", err)
+				return fmt.Errorf("failed to get index status of %s.%s: %w", dbName, tableName, err)
+			}
+
+			ch <- prometheus.MustNewConstMetric(c.secondaryIndexes, prometheus.GaugeValue, float64(len(statuses)), c.e.withTableIDValue(tableID, dbName, tableName)...)
+			for _, status := range statuses {
+				ch <- prometheus.MustNewConstMetric(c.indexReady, prometheus.GaugeValue, boolToFloat(status.Ready), c.e.withTableIDValue(tableID, dbName, tableName, status.Index)...)
+			}
+			return nil
+		})
+	}
+	return wg.Wait()
+}
@@ -0,0 +1,156 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	r "gopkg.in/rethinkdb/rethinkdb-go.v6"
+)
+
+func init() {
+	registerCollector("jobs", true, newJobsCollector)
+}
+
+// jobsCollector collects currently running tasks from the rethinkdb.jobs system table
+type jobsCollector struct {
+	e *RethinkdbExporter
+
+	runningQueries       *prometheus.Desc
+	longestQueryDuration *prometheus.Desc
+
+	backfillProgress          *prometheus.Desc
+	indexConstructionProgress *prometheus.Desc
+}
+
+func newJobsCollector(e *RethinkdbExporter) collector {
+	return &jobsCollector{
+		e: e,
+		runningQueries: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "server", "running_queries"),
+			"Number of queries running on the server",
+			"server"),
+		longestQueryDuration: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "server", "longest_query_duration_seconds"),
+			"Duration of the longest query running on the server",
+			"server"),
+		backfillProgress: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "table", "backfill_progress"),
+			"Completed fraction of the running backfill of the table replica from the source to the destination server",
+			"db", "table", "source_server", "destination_server"),
+		indexConstructionProgress: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "index", "construction_progress"),
+			"Completed fraction of the running construction of the secondary index, the least one of all the replicas",
+			"db", "table", "index"),
+	}
+}
+
+type job struct {
+	Type     string   `rethinkdb:"type"`
+	Duration float64  `rethinkdb:"duration_sec"`
+	Servers  []string `rethinkdb:"servers"`
+	Info     jobInfo  `rethinkdb:"info"`
+}
+
+// jobInfo contains fields of the job info used by different types of the jobs
+type jobInfo struct {
+	Database          string  `rethinkdb:"db"`
+	Table             string  `rethinkdb:"table"`
+	Index             string  `rethinkdb:"index"`
+	SourceServer      string  `rethinkdb:"source_server"`
+	DestinationServer string  `rethinkdb:"destination_server"`
+	Progress          float64 `rethinkdb:"progress"`
+}
+
+type backfillKey struct {
+	db, table, source, destination string
+}
+
+type indexKey struct {
+	db, table, index string
+}
+
+// setMin keeps the least value of the key in the map
+func setMin[K comparable](m map[K]float64, key K, value float64) {
+	current, ok := m[key]
+	if !ok || value < current {
+		m[key] = value
+	}
+}
+
+type serverQueries struct {
+	running         int
+	longestDuration float64
+}
+
+// Describe sends metrics descriptions to the prometheus chan
+func (c *jobsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.runningQueries
+	ch <- c.longestQueryDuration
+
+	ch <- c.backfillProgress
+	ch <- c.indexConstructionProgress
+}
+
+// Update sends collected metrics values to the prometheus chan
+func (c *jobsCollector) Update(ctx context.Context, ch chan<- prometheus.Metric) error {
+	var jobs []job
+	err := c.e.readAll(ctx, r.JobsSystemTable, c.e.systemTable(r.JobsSystemTable), &jobs)
+	if err != nil {
+		return fmt.Errorf("failed to query system jobs table: %w", err)
+	}
+
+	queries := make(map[string]*serverQueries)
+	backfills := make(map[backfillKey]float64)
+	indexes := make(map[indexKey]float64)
+	for _, job := range jobs {
+		switch job.Type {
+		case "query":
+			for _, server := range job.Servers {
+				q, ok := queries[server]
+				if !ok {
+					q = &serverQueries{}
+					queries[server] = q
+				}
+				q.running++
+				q.longestDuration = max(q.longestDuration, job.Duration)
+			}
+		case "backfill":
+			key := backfillKey{
+				db:          job.Info.Database,
+				table:       job.Info.Table,
+				source:      job.Info.SourceServer,
+				destination: job.Info.DestinationServer,
+			}
+			setMin(backfills, key, job.Info.Progress)
+		case "index_construction":
+			key := indexKey{
+				db:    job.Info.Database,
+				table: job.Info.Table,
+				index: job.Info.Index,
+			}
+			setMin(indexes, key, job.Info.Progress)
+		}
+	}
+
+	for server, q := range queries {
+		if !c.e.filters.serverMatches(server) {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.runningQueries, prometheus.GaugeValue, float64(q.running), server)
+		ch <- prometheus.MustNewConstMetric(c.longestQueryDuration, prometheus.GaugeValue, q.longestDuration, server)
+	}
+	for key, progress := range backfills {
+		if !c.e.filters.tableMatches(key.db, key.table) {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.backfillProgress, prometheus.GaugeValue, progress, key.db, key.table, key.source, key.destination)
+	}
+	for key, progress := range indexes {
+		if !c.e.filters.tableMatches(key.db, key.table) {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.indexConstructionProgress, prometheus.GaugeValue, progress, key.db, key.table, key.index)
+	}
+	return nil
+}
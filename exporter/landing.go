@@ -0,0 +1,118 @@
+package exporter
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/prometheus/common/version"
+	"github.com/prometheus/exporter-toolkit/web"
+)
+
+// LandingPageOptions customizes the page served on the / path
+type LandingPageOptions struct {
+	// Disabled serves 404 on the / path instead of the page
+	Disabled bool
+	// Title of the page, "RethinkDB Exporter" if it is empty
+	Title string
+	// Description is shown under the title
+	Description string
+	// Links are added to the links of the exporter's paths, e.g. to the dashboards
+	Links []LandingPageLink
+}
+
+// LandingPageLink is a link shown on the landing page
+type LandingPageLink struct {
+	// Address is used as is, it is not prefixed with the path of the external url
+	Address string
+	// Text of the link
+	Text string
+}
+
+const defaultLandingPageTitle = "RethinkDB Exporter"
+
+var landingPageLinks = template.Must(template.New("links").Parse(`
+      <div>
+        <ul>
+          {{- range . }}
+          <li><a href="{{ .Address }}">{{ .Text }}</a></li>
+          {{- end }}
+        </ul>
+      </div>`))
+
+// validateExternalURL checks that the external url is absolute
+func validateExternalURL(externalURL string) error {
+	if externalURL == "" {
+		return nil
+	}
+	u, err := url.Parse(externalURL)
+	if err != nil {
+		return fmt.Errorf("invalid external url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("external url must be an absolute http url, got %q", externalURL)
+	}
+	return nil
+}
+
+// landingPage creates the handler of the / path. The links are prefixed with the path of the external url,
+// so they work behind a reverse proxy serving the exporter under that path.
+func (e *RethinkdbExporter) landingPage(telemetryPath string) (http.Handler, error) {
+	if e.opts.LandingPage.Disabled {
+		return http.NotFoundHandler(), nil
+	}
+
+	prefix := "/"
+	if e.opts.ExternalURL != "" {
+		// the url is validated with the options
+		u, _ := url.Parse(e.opts.ExternalURL)
+		prefix = strings.TrimSuffix(u.Path, "/") + "/"
+	}
+
+	links := []web.LandingLinks{
+		{Address: telemetryPath, Text: "Metrics"},
+		{Address: "/api/v1/stats", Text: "Stats API"},
+	}
+	if e.opts.MetaTelemetryPath != "" && e.metaGatherer != nil {
+		links = append(links, web.LandingLinks{Address: e.opts.MetaTelemetryPath, Text: "Exporter metrics"})
+	}
+
+	var extra bytes.Buffer
+	if len(e.opts.LandingPage.Links) > 0 {
+		err := landingPageLinks.Execute(&extra, e.opts.LandingPage.Links)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render landing page links: %w", err)
+		}
+	}
+
+	title := e.opts.LandingPage.Title
+	if title == "" {
+		title = defaultLandingPageTitle
+	}
+	page, err := web.NewLandingPage(web.LandingConfig{
+		RoutePrefix: prefix,
+		Name:        html.EscapeString(title),
+		Description: html.EscapeString(e.opts.LandingPage.Description),
+		Version:     version.Info(),
+		Links:       links,
+		ExtraHTML:   extra.String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create landing page: %w", err)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		// the page is served on the / path, the reverse proxy strips the prefix of the external url
+		r = r.Clone(r.Context())
+		r.URL.Path = prefix
+		page.ServeHTTP(w, r)
+	}), nil
+}
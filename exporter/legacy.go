@@ -0,0 +1,94 @@
+package exporter
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// LegacyNamesAdditional exports the metrics under the legacy names besides the current ones
+	LegacyNamesAdditional = "additional"
+	// LegacyNamesExclusive exports the metrics only under the legacy names if they have one
+	LegacyNamesExclusive = "exclusive"
+)
+
+// legacyNames maps names of the stats metrics to their names in the oliver006/rethinkdb_exporter,
+// which prefixed them with the namespace
+var legacyNames = map[string]string{
+	"cluster_client_connections":      "rethinkdb_cluster_client_connections",
+	"cluster_docs_per_second":         "rethinkdb_cluster_docs_per_second",
+	"server_client_connections":       "rethinkdb_server_client_connections",
+	"server_queries_per_second":       "rethinkdb_server_queries_per_second",
+	"server_docs_per_second":          "rethinkdb_server_docs_per_second",
+	"table_docs_per_second":           "rethinkdb_table_docs_per_second",
+	"table_rows_count":                "rethinkdb_table_rows_count",
+	"tablereplica_docs_per_second":    "rethinkdb_tablereplica_docs_per_second",
+	"tablereplica_cache_bytes":        "rethinkdb_tablereplica_cache_bytes",
+	"tablereplica_io":                 "rethinkdb_tablereplica_io",
+	"tablereplica_data_bytes":         "rethinkdb_tablereplica_data_bytes",
+	"tablereplica_metadata_bytes":     "rethinkdb_tablereplica_metadata_bytes",
+	"tablereplica_garbage_bytes":      "rethinkdb_tablereplica_garbage_bytes",
+	"tablereplica_preallocated_bytes": "rethinkdb_tablereplica_preallocated_bytes",
+}
+
+// validateLegacyNames checks the mode of exporting the legacy names
+func validateLegacyNames(mode string) error {
+	switch mode {
+	case "", LegacyNamesAdditional, LegacyNamesExclusive:
+		return nil
+	default:
+		return fmt.Errorf("unknown legacy names mode %q, expected %q or %q", mode, LegacyNamesAdditional, LegacyNamesExclusive)
+	}
+}
+
+// legacyMetric is the metric exported under the legacy name with the same labels and value
+type legacyMetric struct {
+	prometheus.Metric
+	desc *prometheus.Desc
+}
+
+// Desc returns description of the metric with the legacy name
+func (m legacyMetric) Desc() *prometheus.Desc {
+	return m.desc
+}
+
+// addLegacyDesc creates description of the stats metric under the legacy name if it has one
+// differing from the current name, which is the same with the rethinkdb namespace
+func (e *RethinkdbExporter) addLegacyDesc(desc *prometheus.Desc, name, help string, labels []string) {
+	legacyName, ok := legacyNames[name]
+	if !ok || e.opts.LegacyNames == "" || legacyName == e.statsName(name) {
+		return
+	}
+	if e.legacyDescs == nil {
+		e.legacyDescs = make(map[*prometheus.Desc]*prometheus.Desc)
+	}
+	e.legacyDescs[desc] = prometheus.NewDesc(legacyName, help, labels, e.constLabels)
+}
+
+// legacyChan returns the chan exporting metrics under their legacy names besides or instead of the current ones
+// and the function to call after the metrics are sent
+func (e *RethinkdbExporter) legacyChan(ch chan<- prometheus.Metric) (chan<- prometheus.Metric, func()) {
+	if len(e.legacyDescs) == 0 {
+		return ch, func() {}
+	}
+
+	in := make(chan prometheus.Metric)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for m := range in {
+			legacy, ok := e.legacyDescs[m.Desc()]
+			if ok {
+				ch <- legacyMetric{Metric: m, desc: legacy}
+			}
+			if !ok || e.opts.LegacyNames != LegacyNamesExclusive {
+				ch <- m
+			}
+		}
+	}()
+	return in, func() {
+		close(in)
+		<-done
+	}
+}
@@ -0,0 +1,36 @@
+package exporter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+// logLevelHandler returns the current log level, PUT or POST requests change it to the level in the body
+// until the config is reloaded
+func (e *RethinkdbExporter) logLevelHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodPut, http.MethodPost:
+		body, err := io.ReadAll(io.LimitReader(r.Body, 64))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to read log level: %s", err), http.StatusBadRequest)
+			return
+		}
+		var level slog.Level
+		err = level.UnmarshalText(bytes.TrimSpace(body))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid log level: %s", err), http.StatusBadRequest)
+			return
+		}
+		e.opts.LogLevel.Set(level)
+		e.log.Warn("log level changed", "level", level, "remote_addr", r.RemoteAddr)
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		http.Error(w, "only GET, PUT or POST requests allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	_, _ = fmt.Fprintln(w, e.opts.LogLevel.Level())
+}
@@ -0,0 +1,98 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	r "gopkg.in/rethinkdb/rethinkdb-go.v6"
+)
+
+func init() {
+	registerCollector("logs", false, newLogsCollector)
+}
+
+// logsCollector counts new entries of the rethinkdb.logs system table since the exporter started.
+// The logs table is read from the log files of all servers, so it is disabled by default.
+type logsCollector struct {
+	e *RethinkdbExporter
+
+	messages *prometheus.Desc
+
+	m      sync.Mutex
+	since  time.Time
+	counts map[logKey]float64
+}
+
+func newLogsCollector(e *RethinkdbExporter) collector {
+	return &logsCollector{
+		e: e,
+		messages: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "server", "log_messages_total"),
+			"Number of messages logged by the server since the exporter started",
+			"level", "server"),
+		counts: make(map[logKey]float64),
+	}
+}
+
+type logEntry struct {
+	Level     string    `rethinkdb:"level"`
+	Server    string    `rethinkdb:"server"`
+	Timestamp time.Time `rethinkdb:"timestamp"`
+}
+
+type logKey struct {
+	level, server string
+}
+
+// Describe sends metrics descriptions to the prometheus chan
+func (c *logsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.messages
+}
+
+// Update sends collected metrics values to the prometheus chan
+func (c *logsCollector) Update(ctx context.Context, ch chan<- prometheus.Metric) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	err := c.update(ctx)
+
+	for key, count := range c.counts {
+		if !c.e.filters.serverMatches(key.server) {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.messages, prometheus.CounterValue, count, key.level, key.server)
+	}
+	return err
+}
+
+func (c *logsCollector) update(ctx context.Context) error {
+	if c.since.IsZero() {
+		// messages logged before the first scrape are not counted, the cluster's clock is used to skip them
+		err := c.e.readOne(ctx, "now", r.Now(), &c.since)
+		if err != nil {
+			return fmt.Errorf("failed to query current time: %w", err)
+		}
+		return nil
+	}
+
+	var entries []logEntry
+	err := c.e.readAll(ctx, r.LogsSystemTable,
+		c.e.systemTable(r.LogsSystemTable).
+			Filter(r.Row.Field("timestamp").Gt(c.since)).
+			Pluck("level", "server", "timestamp"),
+		&entries)
+	if err != nil {
+		return fmt.Errorf("failed to query system logs table: %w", err)
+	}
+
+	for _, entry := range entries {
+		c.counts[logKey{level: entry.Level, server: entry.Server}]++
+		if entry.Timestamp.After(c.since) {
+			c.since = entry.Timestamp
+		}
+	}
+	return nil
+}
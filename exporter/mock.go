@@ -0,0 +1,562 @@
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	r "gopkg.in/rethinkdb/rethinkdb-go.v6"
+	"gopkg.in/rethinkdb/rethinkdb-go.v6/encoding"
+)
+
+// mockSystemTables are the system tables answered by the mock session, they can be replaced by the fixtures
+var mockSystemTables = []string{
+	r.StatsSystemTable,
+	r.ServerStatusSystemTable,
+	r.TableStatusSystemTable,
+	r.TableConfigSystemTable,
+	r.ServerConfigSystemTable,
+	r.DBConfigSystemTable,
+	r.ClusterConfigSystemTable,
+	r.UsersSystemTable,
+	r.PermissionsSystemTable,
+	r.CurrentIssuesSystemTable,
+	r.JobsSystemTable,
+}
+
+// mockRefreshInterval limits how often the synthetic documents are generated again
+const mockRefreshInterval = time.Second
+
+// MockSession answers the queries of the collectors with synthetic metrics of a demo cluster
+// instead of querying the rethinkdb, so dashboards and alerting rules can be developed without a cluster.
+// It implements r.QueryExecutor interface.
+type MockSession struct {
+	*r.Mock
+
+	cluster  *mockCluster
+	fixtures map[string][]interface{}
+
+	m         sync.Mutex
+	responses []mockResponse
+	refreshed time.Time
+}
+
+// mockResponse answers the expected query from the current snapshot of the cluster
+type mockResponse struct {
+	query  *r.MockQuery
+	answer func(s mockSnapshot) interface{}
+}
+
+// NewMockSession creates the mock session answering the queries built with the options.
+// The documents of the system tables found in the fixtures file replace the generated ones.
+func NewMockSession(opts Options, fixturesFile string) (*MockSession, error) {
+	fixtures, err := readMockFixtures(fixturesFile)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &MockSession{
+		Mock:     r.NewMock(),
+		cluster:  newMockCluster(time.Now()),
+		fixtures: fixtures,
+	}
+
+	// the expected queries are built the same way as by the collectors
+	e := newCollector(slog.New(slog.DiscardHandler), nil, opts)
+	stats := &statsCollector{e: e}
+
+	s.expect(r.Expr(1), func(mockSnapshot) interface{} { return 1 })
+	s.expect(r.Now(), func(snapshot mockSnapshot) interface{} { return snapshot.now })
+	s.expect(stats.statsQuery(), func(snapshot mockSnapshot) interface{} {
+		return snapshot.systemTables[r.StatsSystemTable]
+	})
+	for _, name := range mockSystemTables {
+		s.expect(e.systemTable(name), func(snapshot mockSnapshot) interface{} {
+			return snapshot.systemTables[name]
+		})
+	}
+	s.expect(e.systemTable(r.TableConfigSystemTable).Pluck("id", "db", "name"), func(snapshot mockSnapshot) interface{} {
+		return snapshot.systemTables[r.TableConfigSystemTable]
+	})
+	s.expect(e.systemTable(r.ServerConfigSystemTable).Pluck("id"), func(snapshot mockSnapshot) interface{} {
+		return snapshot.systemTables[r.ServerConfigSystemTable]
+	})
+
+	var configs []tableConfig
+	err = encoding.Decode(&configs, s.snapshot(time.Now()).systemTables[r.TableConfigSystemTable])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode table_config fixtures: %w", err)
+	}
+	for _, config := range configs {
+		key := tableKey{db: config.Database, table: config.Name}
+		shards := len(config.Shards)
+		table := r.DB(config.Database).Table(config.Name)
+		s.expect(table.Info(), func(snapshot mockSnapshot) interface{} {
+			return map[string]interface{}{"doc_count_estimates": snapshot.estimates(key, shards)}
+		})
+		s.expect(table.IndexStatus(), func(snapshot mockSnapshot) interface{} {
+			return snapshot.tables[key].indexes
+		})
+		s.expect(table.Count(), func(snapshot mockSnapshot) interface{} {
+			var count float64
+			for _, estimate := range snapshot.estimates(key, shards) {
+				count += estimate
+			}
+			return count
+		})
+	}
+
+	// other queries, like the one of the logs, return no documents
+	s.Mock.On(r.MockAnything()).Return([]interface{}{}, nil)
+	return s, nil
+}
+
+// readMockFixtures reads the documents of the system tables by their name from the JSON file
+func readMockFixtures(path string) (map[string][]interface{}, error) {
+	if path == "" {
+		return nil, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mock fixtures: %w", err)
+	}
+	var fixtures map[string][]interface{}
+	err = json.Unmarshal(content, &fixtures)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse mock fixtures %s: %w", path, err)
+	}
+	for name := range fixtures {
+		if !slices.Contains(mockSystemTables, name) {
+			return nil, fmt.Errorf("mock fixtures contain unsupported system table %q", name)
+		}
+	}
+	return fixtures, nil
+}
+
+func (s *MockSession) expect(term r.Term, answer func(s mockSnapshot) interface{}) {
+	s.responses = append(s.responses, mockResponse{query: s.Mock.On(term), answer: answer})
+}
+
+// snapshot returns the generated documents with the fixtures applied
+func (s *MockSession) snapshot(now time.Time) mockSnapshot {
+	snapshot := s.cluster.snapshot(now)
+	for name, docs := range s.fixtures {
+		snapshot.systemTables[name] = docs
+	}
+	return snapshot
+}
+
+// Query answers the query with the current documents of the demo cluster
+func (s *MockSession) Query(ctx context.Context, q r.Query) (*r.Cursor, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.refreshed) >= mockRefreshInterval {
+		snapshot := s.snapshot(now)
+		for _, response := range s.responses {
+			response.query.Return(response.answer(snapshot), nil)
+		}
+		s.refreshed = now
+	}
+
+	cur, err := s.Mock.Query(ctx, q)
+	// the mock records every executed query, they are not needed
+	s.Mock.Queries = nil
+	return cur, err
+}
+
+// Exec executes the query without returning its result
+func (s *MockSession) Exec(ctx context.Context, q r.Query) error {
+	cur, err := s.Query(ctx, q)
+	if err != nil {
+		return err
+	}
+	return cur.Close()
+}
+
+// Close does nothing, it is there to be closed the same way as the connections
+func (s *MockSession) Close() error {
+	return nil
+}
+
+// mockSnapshot holds the documents of the demo cluster at the time
+type mockSnapshot struct {
+	now          time.Time
+	systemTables map[string][]interface{}
+	tables       map[tableKey]mockTableSnapshot
+}
+
+type mockTableSnapshot struct {
+	estimates []float64
+	indexes   []interface{}
+}
+
+// estimates returns the docs count estimates of the shards of the table,
+// the tables known only from the fixtures report empty shards
+func (s mockSnapshot) estimates(key tableKey, shards int) []float64 {
+	if t, ok := s.tables[key]; ok {
+		return t.estimates
+	}
+	return make([]float64, shards)
+}
+
+// mockTable is a table of the demo cluster with its base load
+type mockTable struct {
+	db, name string
+	shards   int
+	replicas int
+	// nonvoting is the number of the last replicas of every shard which don't vote
+	nonvoting int
+	rows      float64
+	// reads and writes are the mean numbers of documents per second
+	reads, writes float64
+	indexes       []string
+}
+
+var (
+	mockServers = []string{"rethinkdb_0", "rethinkdb_1", "rethinkdb_2"}
+	// mockProxies serve client connections without storing data, they appear in the stats only
+	mockProxies = []string{"rethinkdb_proxy_0"}
+	mockTables  = []mockTable{
+		{db: "app", name: "users", shards: 2, replicas: 3, rows: 250000, reads: 800, writes: 40, indexes: []string{"email"}},
+		{db: "app", name: "sessions", shards: 1, replicas: 3, nonvoting: 1, rows: 40000, reads: 1500, writes: 300, indexes: []string{"user_id", "expires_at"}},
+		{db: "app", name: "events", shards: 4, replicas: 2, rows: 5000000, reads: 200, writes: 900, indexes: []string{"created_at"}},
+		{db: "analytics", name: "reports", shards: 1, replicas: 1, rows: 1200, reads: 5, writes: 0.5},
+	}
+)
+
+const (
+	// mockPeriod is the period of the load changes of the demo cluster
+	mockPeriod = 10 * time.Minute
+	// mockAmplitude is the relative amplitude of the load changes
+	mockAmplitude = 0.3
+	// mockUptime is how long the servers run before the exporter is started
+	mockUptime = 72 * time.Hour
+	// mockDocSize is the mean size of the documents in bytes
+	mockDocSize = 512
+	// mockInsertedFraction is the fraction of the written documents which are inserted
+	mockInsertedFraction = 0.2
+	// mockIndexCycle is the period of rebuilding the index of the events table,
+	// which takes the first third of the period
+	mockIndexCycle = 15 * time.Minute
+)
+
+// mockCluster generates the documents of the demo cluster, the loads change periodically
+// and the totals grow consistently with them
+type mockCluster struct {
+	started time.Time
+}
+
+func newMockCluster(started time.Time) *mockCluster {
+	return &mockCluster{started: started}
+}
+
+// mockID returns a stable uuid of the object of the kind
+func mockID(kind, i int) string {
+	return fmt.Sprintf("%08x-0000-4000-8000-%012x", kind, i)
+}
+
+// mockRate returns the load of the base at the time since the start shifted by the phase
+func mockRate(base, elapsed, phase float64) float64 {
+	w := 2 * math.Pi / mockPeriod.Seconds()
+	return base * (1 + mockAmplitude*math.Sin(w*elapsed+phase))
+}
+
+// mockTotal returns the integral of the rate since the server started
+func mockTotal(base, elapsed, phase float64) float64 {
+	w := 2 * math.Pi / mockPeriod.Seconds()
+	return base*mockUptime.Seconds() + base*(elapsed+mockAmplitude/w*(math.Cos(phase)-math.Cos(w*elapsed+phase)))
+}
+
+// mockDBConfigs returns the databases of the tables in the order of their first tables
+func mockDBConfigs() []interface{} {
+	var dbs []interface{}
+	seen := make(map[string]bool)
+	for _, t := range mockTables {
+		if seen[t.db] {
+			continue
+		}
+		seen[t.db] = true
+		dbs = append(dbs, map[string]interface{}{"id": mockID(3, len(dbs)), "name": t.db})
+	}
+	return dbs
+}
+
+// replicaShards returns the number of the shards of the table replicated by the server
+func (t mockTable) replicaShards(server int) int {
+	n := 0
+	for shard := 0; shard < t.shards; shard++ {
+		for j := 0; j < t.replicas; j++ {
+			if (shard+j)%len(mockServers) == server {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func (t mockTable) shardReplicas(shard int) []interface{} {
+	replicas := make([]interface{}, 0, t.replicas)
+	for j := 0; j < t.replicas; j++ {
+		replicas = append(replicas, mockServers[(shard+j)%len(mockServers)])
+	}
+	return replicas
+}
+
+func (c *mockCluster) snapshot(now time.Time) mockSnapshot {
+	elapsed := now.Sub(c.started).Seconds()
+	snapshot := mockSnapshot{
+		now:          now,
+		systemTables: make(map[string][]interface{}, len(mockSystemTables)),
+		tables:       make(map[tableKey]mockTableSnapshot, len(mockTables)),
+	}
+
+	type serverLoad struct {
+		reads, writes, readsTotal, writesTotal float64
+	}
+	servers := make([]serverLoad, len(mockServers))
+	var clusterReads, clusterWrites float64
+
+	var stats, statuses, configs []interface{}
+	rebuilding := math.Mod(elapsed, mockIndexCycle.Seconds()) < mockIndexCycle.Seconds()/3
+	for i, t := range mockTables {
+		phase := float64(i)
+		reads, writes := mockRate(t.reads, elapsed, phase), mockRate(t.writes, elapsed, phase)
+		clusterReads += reads
+		clusterWrites += writes
+		stats = append(stats, map[string]interface{}{
+			"id":    []interface{}{"table", mockID(1, i)},
+			"db":    t.db,
+			"table": t.name,
+			"query_engine": map[string]interface{}{
+				"read_docs_per_sec":    reads,
+				"written_docs_per_sec": writes,
+			},
+		})
+
+		// the inserted documents are counted since the exporter started
+		rows := t.rows + mockInsertedFraction*(mockTotal(t.writes, elapsed, phase)-mockTotal(t.writes, 0, phase))
+		estimates := make([]float64, t.shards)
+		for shard := range estimates {
+			estimates[shard] = math.Round(rows / float64(t.shards))
+		}
+		indexes := make([]interface{}, 0, len(t.indexes))
+		for _, index := range t.indexes {
+			ready := !(rebuilding && t.name == "events" && index == "created_at")
+			indexes = append(indexes, map[string]interface{}{"index": index, "ready": ready})
+		}
+		snapshot.tables[tableKey{db: t.db, table: t.name}] = mockTableSnapshot{estimates: estimates, indexes: indexes}
+
+		var statusShards, configShards []interface{}
+		for shard := 0; shard < t.shards; shard++ {
+			replicas := t.shardReplicas(shard)
+			states := make([]interface{}, 0, len(replicas))
+			for _, server := range replicas {
+				states = append(states, map[string]interface{}{"server": server, "state": "ready"})
+			}
+			statusShards = append(statusShards, map[string]interface{}{
+				"primary_replicas": []interface{}{replicas[0]},
+				"replicas":         states,
+			})
+			configShards = append(configShards, map[string]interface{}{
+				"primary_replica":    replicas[0],
+				"replicas":           replicas,
+				"nonvoting_replicas": replicas[len(replicas)-t.nonvoting:],
+			})
+		}
+		statuses = append(statuses, map[string]interface{}{
+			"id":   mockID(1, i),
+			"db":   t.db,
+			"name": t.name,
+			"status": map[string]interface{}{
+				"all_replicas_ready":       true,
+				"ready_for_outdated_reads": true,
+				"ready_for_reads":          true,
+				"ready_for_writes":         true,
+			},
+			"shards": statusShards,
+		})
+		configs = append(configs, map[string]interface{}{
+			"id":         mockID(1, i),
+			"db":         t.db,
+			"name":       t.name,
+			"durability": "hard",
+			"write_acks": "majority",
+			"shards":     configShards,
+		})
+
+		for server, name := range mockServers {
+			shards := t.replicaShards(server)
+			if shards == 0 {
+				continue
+			}
+			// the reads are spread over the replicas, every replica writes all the documents of its shards
+			readShare := float64(shards) / float64(t.shards*t.replicas)
+			writeShare := float64(shards) / float64(t.shards)
+			phase := float64(i) + float64(server)/2
+			load := serverLoad{
+				reads:       mockRate(t.reads, elapsed, phase) * readShare,
+				writes:      mockRate(t.writes, elapsed, phase) * writeShare,
+				readsTotal:  mockTotal(t.reads, elapsed, phase) * readShare,
+				writesTotal: mockTotal(t.writes, elapsed, phase) * writeShare,
+			}
+			servers[server].reads += load.reads
+			servers[server].writes += load.writes
+			servers[server].readsTotal += load.readsTotal
+			servers[server].writesTotal += load.writesTotal
+
+			data := rows * mockDocSize * writeShare
+			stats = append(stats, map[string]interface{}{
+				"id":     []interface{}{"table_server", mockID(1, i), mockID(2, server)},
+				"db":     t.db,
+				"table":  t.name,
+				"server": name,
+				"query_engine": map[string]interface{}{
+					"read_docs_per_sec":    load.reads,
+					"written_docs_per_sec": load.writes,
+					"read_docs_total":      math.Round(load.readsTotal),
+					"written_docs_total":   math.Round(load.writesTotal),
+				},
+				"storage_engine": map[string]interface{}{
+					"cache": map[string]interface{}{
+						"in_use_bytes": math.Min(data/2, 256<<20),
+					},
+					"disk": map[string]interface{}{
+						// most of the reads are served from the cache
+						"read_bytes_per_sec":    load.reads * mockDocSize * 0.3,
+						"written_bytes_per_sec": load.writes * mockDocSize * 1.5,
+						"read_bytes_total":      math.Round(load.readsTotal * mockDocSize * 0.3),
+						"written_bytes_total":   math.Round(load.writesTotal * mockDocSize * 1.5),
+						"space_usage": map[string]interface{}{
+							"data_bytes":         math.Round(data),
+							"metadata_bytes":     math.Round(data * 0.02),
+							"garbage_bytes":      math.Round(mockRate(data*0.05, elapsed, phase)),
+							"preallocated_bytes": 32 << 20,
+						},
+					},
+				},
+			})
+		}
+	}
+
+	clusterConnections := 0.0
+	var serverStatuses, serverConfigs, jobs []interface{}
+	for server, name := range mockServers {
+		phase := float64(server)
+		connections := math.Round(mockRate(20, elapsed, phase))
+		clusterConnections += connections
+		load := servers[server]
+		stats = append(stats, map[string]interface{}{
+			"id":     []interface{}{"server", mockID(2, server)},
+			"server": name,
+			"query_engine": map[string]interface{}{
+				"client_connections":   connections,
+				"queries_per_sec":      (load.reads + load.writes) / 4,
+				"read_docs_per_sec":    load.reads,
+				"written_docs_per_sec": load.writes,
+				"queries_total":        math.Round((load.readsTotal + load.writesTotal) / 4),
+				"read_docs_total":      math.Round(load.readsTotal),
+				"written_docs_total":   math.Round(load.writesTotal),
+			},
+		})
+		serverConfigs = append(serverConfigs, map[string]interface{}{
+			"id":            mockID(2, server),
+			"name":          name,
+			"tags":          []interface{}{"default"},
+			"cache_size_mb": "auto",
+		})
+		serverStatuses = append(serverStatuses, map[string]interface{}{
+			"id":   mockID(2, server),
+			"name": name,
+			"process": map[string]interface{}{
+				"cache_size_mb": 1024,
+				"pid":           1000 + server,
+				"time_started":  c.started.Add(-mockUptime - time.Duration(server)*time.Hour),
+				"version":       "rethinkdb 2.4.4~0bookworm (GCC 12.2.0)",
+			},
+		})
+		for q := 0; q < 2+server; q++ {
+			jobs = append(jobs, map[string]interface{}{
+				"type":         "query",
+				"duration_sec": mockRate(0.05*float64(q+1), elapsed, phase),
+				"servers":      []interface{}{name},
+				"info":         map[string]interface{}{},
+			})
+		}
+	}
+	for proxy, name := range mockProxies {
+		// the proxies forward the queries of their clients, the documents are counted by the data servers
+		phase := float64(len(mockServers) + proxy)
+		connections := math.Round(mockRate(60, elapsed, phase))
+		clusterConnections += connections
+		stats = append(stats, map[string]interface{}{
+			"id":     []interface{}{"server", mockID(2, len(mockServers)+proxy)},
+			"server": name,
+			"query_engine": map[string]interface{}{
+				"client_connections":   connections,
+				"queries_per_sec":      mockRate(450, elapsed, phase),
+				"read_docs_per_sec":    0,
+				"written_docs_per_sec": 0,
+				"queries_total":        math.Round(mockTotal(450, elapsed, phase)),
+				"read_docs_total":      0,
+				"written_docs_total":   0,
+			},
+		})
+	}
+	if rebuilding {
+		progress := math.Mod(elapsed, mockIndexCycle.Seconds()) / (mockIndexCycle.Seconds() / 3)
+		jobs = append(jobs, map[string]interface{}{
+			"type":         "index_construction",
+			"duration_sec": math.Mod(elapsed, mockIndexCycle.Seconds()),
+			"servers":      []interface{}{mockServers[0], mockServers[1]},
+			"info": map[string]interface{}{
+				"db":       "app",
+				"table":    "events",
+				"index":    "created_at",
+				"progress": progress,
+			},
+		})
+	}
+
+	stats = append(stats, map[string]interface{}{
+		"id": []interface{}{"cluster"},
+		"query_engine": map[string]interface{}{
+			"client_connections":   clusterConnections,
+			"read_docs_per_sec":    clusterReads,
+			"written_docs_per_sec": clusterWrites,
+		},
+	})
+
+	snapshot.systemTables[r.StatsSystemTable] = stats
+	snapshot.systemTables[r.ServerStatusSystemTable] = serverStatuses
+	snapshot.systemTables[r.TableStatusSystemTable] = statuses
+	snapshot.systemTables[r.TableConfigSystemTable] = configs
+	snapshot.systemTables[r.ServerConfigSystemTable] = serverConfigs
+	snapshot.systemTables[r.DBConfigSystemTable] = mockDBConfigs()
+	snapshot.systemTables[r.UsersSystemTable] = []interface{}{
+		map[string]interface{}{"id": "admin", "password": true},
+		map[string]interface{}{"id": "app", "password": true},
+		map[string]interface{}{"id": "monitoring", "password": true},
+	}
+	snapshot.systemTables[r.PermissionsSystemTable] = []interface{}{
+		map[string]interface{}{"id": []interface{}{"admin"}, "user": "admin",
+			"permissions": map[string]interface{}{"read": true, "write": true, "config": true, "connect": true}},
+		map[string]interface{}{"id": []interface{}{"monitoring"}, "user": "monitoring",
+			"permissions": map[string]interface{}{"read": true}},
+		map[string]interface{}{"id": []interface{}{"app", mockID(3, 0)}, "user": "app", "database": "app",
+			"permissions": map[string]interface{}{"read": true, "write": true}},
+	}
+	snapshot.systemTables[r.ClusterConfigSystemTable] = []interface{}{
+		map[string]interface{}{"id": "heartbeat", "heartbeat_timeout_secs": 10},
+	}
+	snapshot.systemTables[r.CurrentIssuesSystemTable] = []interface{}{}
+	snapshot.systemTables[r.JobsSystemTable] = jobs
+	return snapshot
+}
@@ -0,0 +1,206 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"time"
+
+	"github.com/prometheus/common/version"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// protocols of pushing to the OpenTelemetry collector
+const (
+	// OTLPProtocolHTTP is OTLP/HTTP with the protobuf encoding
+	OTLPProtocolHTTP = "http/protobuf"
+	// OTLPProtocolGRPC is OTLP/gRPC
+	OTLPProtocolGRPC = "grpc"
+)
+
+// OTLPOptions defines pushing of the metrics to an OpenTelemetry collector
+type OTLPOptions struct {
+	// Endpoint is the url of the collector, the metrics are not pushed if it is empty.
+	// With OTLP/HTTP it includes the path, e.g. http://otel-collector:4318/v1/metrics,
+	// with OTLP/gRPC it doesn't, e.g. http://otel-collector:4317. The http scheme disables TLS.
+	Endpoint string
+	// Protocol is OTLPProtocolHTTP or OTLPProtocolGRPC, OTLP/HTTP if it is empty
+	Protocol string
+	// Interval of pushing the metrics
+	Interval time.Duration
+	// Headers are added to the push requests, e.g. for authentication
+	Headers map[string]string
+}
+
+// otlpOutput pushes the metrics to the collector with the OpenTelemetry OTLP exporter
+type otlpOutput struct {
+	exporter sdkmetric.Exporter
+	resource *resource.Resource
+	started  time.Time
+}
+
+func newOTLPOutput(opts OTLPOptions) (*otlpOutput, error) {
+	u, err := url.Parse(opts.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid otlp endpoint: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("otlp endpoint must be an http or https url, got scheme %q", u.Scheme)
+	}
+	if opts.Interval <= 0 {
+		return nil, fmt.Errorf("otlp push interval must be positive, got %s", opts.Interval)
+	}
+
+	var exporter sdkmetric.Exporter
+	switch opts.Protocol {
+	case "", OTLPProtocolHTTP:
+		exporter, err = otlpmetrichttp.New(context.Background(),
+			otlpmetrichttp.WithEndpointURL(opts.Endpoint),
+			otlpmetrichttp.WithHeaders(opts.Headers),
+			otlpmetrichttp.WithTimeout(opts.Interval))
+	case OTLPProtocolGRPC:
+		exporter, err = otlpmetricgrpc.New(context.Background(),
+			otlpmetricgrpc.WithEndpointURL(opts.Endpoint),
+			otlpmetricgrpc.WithHeaders(opts.Headers),
+			otlpmetricgrpc.WithTimeout(opts.Interval))
+	default:
+		return nil, fmt.Errorf("unknown otlp protocol %q, must be %q or %q", opts.Protocol, OTLPProtocolHTTP, OTLPProtocolGRPC)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create otlp exporter: %w", err)
+	}
+
+	return &otlpOutput{
+		exporter: exporter,
+		resource: resource.NewSchemaless(attribute.String("service.name", "rethinkdb-exporter")),
+		started:  time.Now(),
+	}, nil
+}
+
+func (o *otlpOutput) push(ctx context.Context, families []*dto.MetricFamily) error {
+	err := o.exporter.Export(ctx, o.resourceMetrics(families, time.Now()))
+	if err != nil {
+		return fmt.Errorf("failed to push to otlp collector: %w", err)
+	}
+	return nil
+}
+
+// resourceMetrics converts the metric families to the OpenTelemetry metrics,
+// the start time of sums, histograms and summaries is the created timestamp if it is known
+func (o *otlpOutput) resourceMetrics(families []*dto.MetricFamily, now time.Time) *metricdata.ResourceMetrics {
+	metrics := make([]metricdata.Metrics, 0, len(families))
+	for _, f := range families {
+		m := metricdata.Metrics{Name: f.GetName(), Description: f.GetHelp()}
+		switch f.GetType() {
+		case dto.MetricType_COUNTER:
+			sum := metricdata.Sum[float64]{Temporality: metricdata.CumulativeTemporality, IsMonotonic: true}
+			for _, metric := range f.GetMetric() {
+				sum.DataPoints = append(sum.DataPoints, metricdata.DataPoint[float64]{
+					Attributes: otlpAttributes(metric.GetLabel()),
+					StartTime:  o.startTime(metric.GetCounter().GetCreatedTimestamp().AsTime()),
+					Time:       now,
+					Value:      metric.GetCounter().GetValue(),
+				})
+			}
+			m.Data = sum
+		case dto.MetricType_GAUGE, dto.MetricType_UNTYPED:
+			gauge := metricdata.Gauge[float64]{}
+			for _, metric := range f.GetMetric() {
+				value := metric.GetGauge().GetValue()
+				if f.GetType() == dto.MetricType_UNTYPED {
+					value = metric.GetUntyped().GetValue()
+				}
+				gauge.DataPoints = append(gauge.DataPoints, metricdata.DataPoint[float64]{
+					Attributes: otlpAttributes(metric.GetLabel()),
+					Time:       now,
+					Value:      value,
+				})
+			}
+			m.Data = gauge
+		case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+			histogram := metricdata.Histogram[float64]{Temporality: metricdata.CumulativeTemporality}
+			for _, metric := range f.GetMetric() {
+				histogram.DataPoints = append(histogram.DataPoints, o.histogramDataPoint(metric, now))
+			}
+			m.Data = histogram
+		case dto.MetricType_SUMMARY:
+			summary := metricdata.Summary{}
+			for _, metric := range f.GetMetric() {
+				s := metric.GetSummary()
+				point := metricdata.SummaryDataPoint{
+					Attributes: otlpAttributes(metric.GetLabel()),
+					StartTime:  o.startTime(s.GetCreatedTimestamp().AsTime()),
+					Time:       now,
+					Count:      s.GetSampleCount(),
+					Sum:        s.GetSampleSum(),
+				}
+				for _, q := range s.GetQuantile() {
+					point.QuantileValues = append(point.QuantileValues, metricdata.QuantileValue{Quantile: q.GetQuantile(), Value: q.GetValue()})
+				}
+				summary.DataPoints = append(summary.DataPoints, point)
+			}
+			m.Data = summary
+		default:
+			continue
+		}
+		metrics = append(metrics, m)
+	}
+
+	return &metricdata.ResourceMetrics{
+		Resource: o.resource,
+		ScopeMetrics: []metricdata.ScopeMetrics{{
+			Scope:   instrumentation.Scope{Name: "github.com/rethinkdb/prometheus-exporter", Version: version.Version},
+			Metrics: metrics,
+		}},
+	}
+}
+
+// histogramDataPoint converts the cumulative buckets of the histogram to the counts of every bucket
+func (o *otlpOutput) histogramDataPoint(metric *dto.Metric, now time.Time) metricdata.HistogramDataPoint[float64] {
+	h := metric.GetHistogram()
+	point := metricdata.HistogramDataPoint[float64]{
+		Attributes:   otlpAttributes(metric.GetLabel()),
+		StartTime:    o.startTime(h.GetCreatedTimestamp().AsTime()),
+		Time:         now,
+		Count:        h.GetSampleCount(),
+		Sum:          h.GetSampleSum(),
+		Bounds:       []float64{},
+		BucketCounts: []uint64{},
+	}
+	var previous uint64
+	for _, b := range h.GetBucket() {
+		if math.IsInf(b.GetUpperBound(), 1) {
+			break
+		}
+		point.Bounds = append(point.Bounds, b.GetUpperBound())
+		point.BucketCounts = append(point.BucketCounts, b.GetCumulativeCount()-previous)
+		previous = b.GetCumulativeCount()
+	}
+	point.BucketCounts = append(point.BucketCounts, h.GetSampleCount()-previous)
+	return point
+}
+
+func otlpAttributes(labels []*dto.LabelPair) attribute.Set {
+	attributes := make([]attribute.KeyValue, 0, len(labels))
+	for _, l := range labels {
+		attributes = append(attributes, attribute.String(l.GetName(), l.GetValue()))
+	}
+	return attribute.NewSet(attributes...)
+}
+
+// startTime returns the created timestamp of the metric if it is known, the start of the exporter otherwise
+func (o *otlpOutput) startTime(created time.Time) time.Time {
+	if created.Unix() <= 0 {
+		return o.started
+	}
+	return created
+}
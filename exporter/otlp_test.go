@@ -0,0 +1,439 @@
+package exporter
+
+import (
+	"context"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
+	colmetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricpb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// otlpReceiver records the requests received by the fake collector
+type otlpReceiver struct {
+	colmetricpb.UnimplementedMetricsServiceServer
+	requests      chan *colmetricpb.ExportMetricsServiceRequest
+	authorization chan string
+}
+
+func newOTLPReceiver() *otlpReceiver {
+	return &otlpReceiver{
+		requests:      make(chan *colmetricpb.ExportMetricsServiceRequest, 1),
+		authorization: make(chan string, 1),
+	}
+}
+
+func (r *otlpReceiver) Export(ctx context.Context, req *colmetricpb.ExportMetricsServiceRequest) (*colmetricpb.ExportMetricsServiceResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	r.authorization <- firstOrEmpty(md.Get("authorization"))
+	r.requests <- req
+	return &colmetricpb.ExportMetricsServiceResponse{}, nil
+}
+
+func (r *otlpReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var export colmetricpb.ExportMetricsServiceRequest
+	err = proto.Unmarshal(body, &export)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.authorization <- req.Header.Get("Authorization")
+	r.requests <- &export
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	resp, _ := proto.Marshal(&colmetricpb.ExportMetricsServiceResponse{})
+	_, _ = w.Write(resp)
+}
+
+func firstOrEmpty(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// serveOTLP starts the fake collector with the protocol and returns its endpoint
+func serveOTLP(t *testing.T, protocol string, receiver *otlpReceiver) string {
+	t.Helper()
+	if protocol == OTLPProtocolGRPC {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := grpc.NewServer()
+		colmetricpb.RegisterMetricsServiceServer(s, receiver)
+		go func() { _ = s.Serve(l) }()
+		t.Cleanup(s.Stop)
+		return "http://" + l.Addr().String()
+	}
+	s := httptest.NewServer(receiver)
+	t.Cleanup(s.Close)
+	return s.URL + "/v1/metrics"
+}
+
+func TestOTLPOutputPush(t *testing.T) {
+	created := time.Unix(1700000000, 0)
+	started := time.Unix(1600000000, 0)
+
+	families := []*dto.MetricFamily{
+		{
+			Name: proto.String("rethinkdb_cluster_queries_total"),
+			Help: proto.String("Total number of queries"),
+			Type: dto.MetricType_COUNTER.Enum(),
+			Metric: []*dto.Metric{{
+				Label:   []*dto.LabelPair{{Name: proto.String("server"), Value: proto.String("s1")}},
+				Counter: &dto.Counter{Value: proto.Float64(42), CreatedTimestamp: timestamppb.New(created)},
+			}},
+		},
+		{
+			Name: proto.String("rethinkdb_server_up"),
+			Help: proto.String("Whether the server is up"),
+			Type: dto.MetricType_GAUGE.Enum(),
+			Metric: []*dto.Metric{{
+				Label: []*dto.LabelPair{
+					{Name: proto.String("server"), Value: proto.String("s1")},
+					{Name: proto.String("cluster"), Value: proto.String("main")},
+				},
+				Gauge: &dto.Gauge{Value: proto.Float64(1)},
+			}},
+		},
+		{
+			Name:   proto.String("scrape_latency"),
+			Help:   proto.String("Latency of collecting scrape"),
+			Type:   dto.MetricType_UNTYPED.Enum(),
+			Metric: []*dto.Metric{{Untyped: &dto.Untyped{Value: proto.Float64(0.5)}}},
+		},
+		{
+			Name: proto.String("rethinkdb_exporter_scrape_duration_seconds"),
+			Help: proto.String("Duration of collecting scrapes"),
+			Type: dto.MetricType_HISTOGRAM.Enum(),
+			Metric: []*dto.Metric{{
+				Histogram: &dto.Histogram{
+					SampleCount: proto.Uint64(5),
+					SampleSum:   proto.Float64(2.5),
+					Bucket: []*dto.Bucket{
+						{UpperBound: proto.Float64(0.1), CumulativeCount: proto.Uint64(1)},
+						{UpperBound: proto.Float64(1), CumulativeCount: proto.Uint64(4)},
+						{UpperBound: proto.Float64(math.Inf(1)), CumulativeCount: proto.Uint64(5)},
+					},
+				},
+			}},
+		},
+		{
+			Name: proto.String("rethinkdb_query_duration_seconds"),
+			Help: proto.String("Duration of queries"),
+			Type: dto.MetricType_SUMMARY.Enum(),
+			Metric: []*dto.Metric{{
+				Summary: &dto.Summary{
+					SampleCount:      proto.Uint64(3),
+					SampleSum:        proto.Float64(0.3),
+					Quantile:         []*dto.Quantile{{Quantile: proto.Float64(0.5), Value: proto.Float64(0.1)}},
+					CreatedTimestamp: timestamppb.New(created),
+				},
+			}},
+		},
+	}
+
+	str := func(k, v string) *commonpb.KeyValue {
+		return &commonpb.KeyValue{Key: k, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}}}
+	}
+	wantMetrics := []*metricpb.Metric{
+		{
+			Name:        "rethinkdb_cluster_queries_total",
+			Description: "Total number of queries",
+			Data: &metricpb.Metric_Sum{Sum: &metricpb.Sum{
+				AggregationTemporality: metricpb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+				IsMonotonic:            true,
+				DataPoints: []*metricpb.NumberDataPoint{{
+					Attributes:        []*commonpb.KeyValue{str("server", "s1")},
+					StartTimeUnixNano: uint64(created.UnixNano()),
+					Value:             &metricpb.NumberDataPoint_AsDouble{AsDouble: 42},
+				}},
+			}},
+		},
+		{
+			Name:        "rethinkdb_server_up",
+			Description: "Whether the server is up",
+			Data: &metricpb.Metric_Gauge{Gauge: &metricpb.Gauge{
+				DataPoints: []*metricpb.NumberDataPoint{{
+					Attributes: []*commonpb.KeyValue{str("cluster", "main"), str("server", "s1")},
+					Value:      &metricpb.NumberDataPoint_AsDouble{AsDouble: 1},
+				}},
+			}},
+		},
+		{
+			Name:        "scrape_latency",
+			Description: "Latency of collecting scrape",
+			Data: &metricpb.Metric_Gauge{Gauge: &metricpb.Gauge{
+				DataPoints: []*metricpb.NumberDataPoint{{
+					Value: &metricpb.NumberDataPoint_AsDouble{AsDouble: 0.5},
+				}},
+			}},
+		},
+		{
+			Name:        "rethinkdb_exporter_scrape_duration_seconds",
+			Description: "Duration of collecting scrapes",
+			Data: &metricpb.Metric_Histogram{Histogram: &metricpb.Histogram{
+				AggregationTemporality: metricpb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+				DataPoints: []*metricpb.HistogramDataPoint{{
+					StartTimeUnixNano: uint64(started.UnixNano()),
+					Count:             5,
+					Sum:               proto.Float64(2.5),
+					ExplicitBounds:    []float64{0.1, 1},
+					BucketCounts:      []uint64{1, 3, 1},
+				}},
+			}},
+		},
+		{
+			Name:        "rethinkdb_query_duration_seconds",
+			Description: "Duration of queries",
+			Data: &metricpb.Metric_Summary{Summary: &metricpb.Summary{
+				DataPoints: []*metricpb.SummaryDataPoint{{
+					StartTimeUnixNano: uint64(created.UnixNano()),
+					Count:             3,
+					Sum:               0.3,
+					QuantileValues:    []*metricpb.SummaryDataPoint_ValueAtQuantile{{Quantile: 0.5, Value: 0.1}},
+				}},
+			}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		protocol string
+	}{
+		{name: "http", protocol: OTLPProtocolHTTP},
+		{name: "default protocol", protocol: ""},
+		{name: "grpc", protocol: OTLPProtocolGRPC},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := newOTLPReceiver()
+			o, err := newOTLPOutput(OTLPOptions{
+				Endpoint: serveOTLP(t, tt.protocol, receiver),
+				Protocol: tt.protocol,
+				Interval: 10 * time.Second,
+				Headers:  map[string]string{"Authorization": "Bearer token"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			o.started = started
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			before := time.Now()
+			err = o.push(ctx, families)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := <-receiver.authorization; got != "Bearer token" {
+				t.Errorf("authorization header = %q, want %q", got, "Bearer token")
+			}
+			req := <-receiver.requests
+			if len(req.GetResourceMetrics()) != 1 || len(req.GetResourceMetrics()[0].GetScopeMetrics()) != 1 {
+				t.Fatalf("want one resource and scope, got %s", protojson.Format(req))
+			}
+			rm := req.GetResourceMetrics()[0]
+			wantResource := &resourcepb.Resource{Attributes: []*commonpb.KeyValue{str("service.name", "rethinkdb-exporter")}}
+			if !proto.Equal(rm.GetResource(), wantResource) {
+				t.Errorf("resource = %s, want %s", protojson.Format(rm.GetResource()), protojson.Format(wantResource))
+			}
+			scope := rm.GetScopeMetrics()[0]
+			if got := scope.GetScope().GetName(); got != "github.com/rethinkdb/prometheus-exporter" {
+				t.Errorf("scope name = %q", got)
+			}
+
+			got := scope.GetMetrics()
+			if len(got) != len(wantMetrics) {
+				t.Fatalf("got %d metrics, want %d", len(got), len(wantMetrics))
+			}
+			for i, m := range got {
+				for _, ts := range dataPointTimes(m) {
+					if *ts < uint64(before.UnixNano()) {
+						t.Errorf("%s: time %d is before the push", m.GetName(), *ts)
+					}
+					*ts = 0
+				}
+				if !proto.Equal(m, wantMetrics[i]) {
+					t.Errorf("metric %d = %s, want %s", i, protojson.Format(m), protojson.Format(wantMetrics[i]))
+				}
+			}
+		})
+	}
+}
+
+// dataPointTimes returns pointers to the times of the data points of the metric
+func dataPointTimes(m *metricpb.Metric) []*uint64 {
+	var times []*uint64
+	for _, p := range m.GetGauge().GetDataPoints() {
+		times = append(times, &p.TimeUnixNano)
+	}
+	for _, p := range m.GetSum().GetDataPoints() {
+		times = append(times, &p.TimeUnixNano)
+	}
+	for _, p := range m.GetHistogram().GetDataPoints() {
+		times = append(times, &p.TimeUnixNano)
+	}
+	for _, p := range m.GetSummary().GetDataPoints() {
+		times = append(times, &p.TimeUnixNano)
+	}
+	return times
+}
+
+func TestNewOTLPOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    OTLPOptions
+		wantErr bool
+	}{
+		{name: "http", opts: OTLPOptions{Endpoint: "http://localhost:4318/v1/metrics", Interval: time.Second}},
+		{name: "https grpc", opts: OTLPOptions{Endpoint: "https://localhost:4317", Protocol: OTLPProtocolGRPC, Interval: time.Second}},
+		{name: "no scheme", opts: OTLPOptions{Endpoint: "localhost:4317", Interval: time.Second}, wantErr: true},
+		{name: "unknown protocol", opts: OTLPOptions{Endpoint: "http://localhost:4318", Protocol: "http/json", Interval: time.Second}, wantErr: true},
+		{name: "no interval", opts: OTLPOptions{Endpoint: "http://localhost:4318/v1/metrics"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newOTLPOutput(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("newOTLPOutput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOTLPResourceMetrics(t *testing.T) {
+	started := time.Unix(1600000000, 0)
+	created := time.Unix(1700000000, 0)
+	now := time.Unix(1800000000, 0)
+
+	tests := []struct {
+		name   string
+		family *dto.MetricFamily
+		want   []metricdata.Metrics
+	}{
+		{
+			name: "counter without created timestamp",
+			family: &dto.MetricFamily{
+				Name:   proto.String("rethinkdb_server_queries_total"),
+				Help:   proto.String("Total number of queries"),
+				Type:   dto.MetricType_COUNTER.Enum(),
+				Metric: []*dto.Metric{{Counter: &dto.Counter{Value: proto.Float64(7)}}},
+			},
+			want: []metricdata.Metrics{{
+				Name:        "rethinkdb_server_queries_total",
+				Description: "Total number of queries",
+				Data: metricdata.Sum[float64]{
+					Temporality: metricdata.CumulativeTemporality,
+					IsMonotonic: true,
+					DataPoints:  []metricdata.DataPoint[float64]{{StartTime: started, Time: now, Value: 7}},
+				},
+			}},
+		},
+		{
+			name: "gauge not finite",
+			family: &dto.MetricFamily{
+				Name: proto.String("rethinkdb_table_docs_estimate"),
+				Type: dto.MetricType_GAUGE.Enum(),
+				Metric: []*dto.Metric{{
+					Label: []*dto.LabelPair{{Name: proto.String("table"), Value: proto.String("events")}},
+					Gauge: &dto.Gauge{Value: proto.Float64(math.Inf(1))},
+				}},
+			},
+			want: []metricdata.Metrics{{
+				Name: "rethinkdb_table_docs_estimate",
+				Data: metricdata.Gauge[float64]{
+					DataPoints: []metricdata.DataPoint[float64]{{
+						Attributes: attribute.NewSet(attribute.String("table", "events")),
+						Time:       now,
+						Value:      math.Inf(1),
+					}},
+				},
+			}},
+		},
+		{
+			name: "histogram without buckets",
+			family: &dto.MetricFamily{
+				Name: proto.String("rethinkdb_exporter_query_duration_seconds"),
+				Type: dto.MetricType_HISTOGRAM.Enum(),
+				Metric: []*dto.Metric{{Histogram: &dto.Histogram{
+					SampleCount:      proto.Uint64(2),
+					SampleSum:        proto.Float64(0.2),
+					Bucket:           []*dto.Bucket{{UpperBound: proto.Float64(math.Inf(1)), CumulativeCount: proto.Uint64(2)}},
+					CreatedTimestamp: timestamppb.New(created),
+				}}},
+			},
+			want: []metricdata.Metrics{{
+				Name: "rethinkdb_exporter_query_duration_seconds",
+				Data: metricdata.Histogram[float64]{
+					Temporality: metricdata.CumulativeTemporality,
+					DataPoints: []metricdata.HistogramDataPoint[float64]{{
+						StartTime:    created,
+						Time:         now,
+						Count:        2,
+						Sum:          0.2,
+						Bounds:       []float64{},
+						BucketCounts: []uint64{2},
+					}},
+				},
+			}},
+		},
+		{
+			name: "summary without quantiles",
+			family: &dto.MetricFamily{
+				Name:   proto.String("rethinkdb_query_duration_seconds"),
+				Type:   dto.MetricType_SUMMARY.Enum(),
+				Metric: []*dto.Metric{{Summary: &dto.Summary{SampleCount: proto.Uint64(1), SampleSum: proto.Float64(0.1)}}},
+			},
+			want: []metricdata.Metrics{{
+				Name: "rethinkdb_query_duration_seconds",
+				Data: metricdata.Summary{
+					DataPoints: []metricdata.SummaryDataPoint{{StartTime: started, Time: now, Count: 1, Sum: 0.1}},
+				},
+			}},
+		},
+		{
+			name: "unknown type",
+			family: &dto.MetricFamily{
+				Name:   proto.String("unknown"),
+				Type:   dto.MetricType(100).Enum(),
+				Metric: []*dto.Metric{{}},
+			},
+			want: []metricdata.Metrics{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &otlpOutput{started: started}
+			got := o.resourceMetrics([]*dto.MetricFamily{tt.family}, now)
+			if len(got.ScopeMetrics) != 1 {
+				t.Fatalf("got %d scopes, want 1", len(got.ScopeMetrics))
+			}
+			want := metricdata.ScopeMetrics{Scope: got.ScopeMetrics[0].Scope, Metrics: tt.want}
+			metricdatatest.AssertEqual(t, want, got.ScopeMetrics[0])
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package exporter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// output pushes the gathered metrics to a monitoring system instead of being scraped
+type output interface {
+	push(ctx context.Context, families []*dto.MetricFamily) error
+}
+
+// scheduledOutput is an output pushing the metrics on its interval
+type scheduledOutput struct {
+	name     string
+	interval time.Duration
+	output   output
+}
+
+// newOutputs creates the outputs enabled in the options
+func newOutputs(opts Options) ([]scheduledOutput, error) {
+	var outputs []scheduledOutput
+	if opts.OTLP.Endpoint != "" {
+		o, err := newOTLPOutput(opts.OTLP)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, scheduledOutput{name: "otlp", interval: opts.OTLP.Interval, output: o})
+	}
+	if opts.Pushgateway.URL != "" {
+		o, err := newPushgatewayOutput(opts.Pushgateway)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, scheduledOutput{name: "pushgateway", interval: opts.Pushgateway.Interval, output: o})
+	}
+	if opts.Graphite.Address != "" {
+		o, err := newGraphiteOutput(opts.Graphite)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, scheduledOutput{name: "graphite", interval: opts.Graphite.Interval, output: o})
+	}
+	if opts.StatsD.Address != "" {
+		o, err := newStatsDOutput(opts.StatsD)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, scheduledOutput{name: "statsd", interval: opts.StatsD.Interval, output: o})
+	}
+	if opts.Textfile.Dir != "" {
+		o, err := newTextfileOutput(opts.Textfile)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, scheduledOutput{name: "textfile", interval: opts.Textfile.Interval, output: o})
+	}
+	return outputs, nil
+}
+
+// pushLoop gathers the metrics and pushes them to the output on its interval until the context is done
+func (e *RethinkdbExporter) pushLoop(ctx context.Context, o scheduledOutput) {
+	ticker := time.NewTicker(o.interval)
+	defer ticker.Stop()
+
+	for {
+		err := e.pushOnce(ctx, o)
+		if err != nil && ctx.Err() == nil {
+			e.log.Error("failed to push metrics", "output", o.name, "error", err)
+			e.self.pushErrors.WithLabelValues(o.name).Inc()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pushFinal collects the metrics and pushes them to all outputs once more after their loops are stopped,
+// the pushes are limited by the interval of the output and the context
+func (e *RethinkdbExporter) pushFinal(ctx context.Context) {
+	if len(e.outputs) == 0 {
+		return
+	}
+	if e.cache != nil {
+		// the background collection is stopped, the cache would serve the metrics of its last interval
+		e.collectToCache(ctx)
+	}
+
+	wg := sync.WaitGroup{}
+	for _, o := range e.outputs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := e.pushOnce(ctx, o)
+			if err != nil {
+				e.log.Error("failed to push metrics on shutdown", "output", o.name, "error", err)
+				e.self.pushErrors.WithLabelValues(o.name).Inc()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// pushOnce gathers the metrics including the exporter's own ones and pushes them to the output
+func (e *RethinkdbExporter) pushOnce(ctx context.Context, o scheduledOutput) error {
+	ctx, cancel := context.WithTimeout(ctx, o.interval)
+	defer cancel()
+
+	gatherer, err := e.gatherer(ctx, true)
+	if err != nil {
+		return err
+	}
+	families, err := gatherer.Gather()
+	if err != nil && len(families) == 0 {
+		return err
+	}
+	if err != nil {
+		e.log.Warn("metrics gathered with errors", "output", o.name, "error", err)
+	}
+	return o.output.push(ctx, families)
+}
@@ -0,0 +1,57 @@
+package exporter
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rethinkdb/prometheus-exporter/dbconnector"
+)
+
+// poolStatser is implemented by connections reporting stats of their pool
+type poolStatser interface {
+	PoolStats() dbconnector.PoolStats
+}
+
+// poolCollector exports stats of the connection pool of the current collector
+type poolCollector struct {
+	e *RethinkdbExporter
+
+	size             *prometheus.Desc
+	connected        *prometheus.Desc
+	connectionErrors *prometheus.Desc
+}
+
+func newPoolCollector(e *RethinkdbExporter) *poolCollector {
+	return &poolCollector{
+		e: e,
+		size: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, exporterSubsystem, "pool_size"),
+			"Maximal number of connections to each rethinkdb node",
+			nil, nil),
+		connected: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, exporterSubsystem, "pool_connected"),
+			"Whether the exporter is connected to the rethinkdb",
+			nil, nil),
+		connectionErrors: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, exporterSubsystem, "pool_connection_errors_total"),
+			"Total number of failed connection attempts and connections closed during queries",
+			nil, nil),
+	}
+}
+
+// Describe sends metrics descriptions to the prometheus chan
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.size
+	ch <- c.connected
+	ch <- c.connectionErrors
+}
+
+// Collect sends metrics values to the prometheus chan
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	pool, ok := c.e.current().rconn.(poolStatser)
+	if !ok {
+		return
+	}
+	stats := pool.PoolStats()
+	ch <- prometheus.MustNewConstMetric(c.size, prometheus.GaugeValue, float64(stats.MaxOpen))
+	ch <- prometheus.MustNewConstMetric(c.connected, prometheus.GaugeValue, boolToFloat(stats.Connected))
+	ch <- prometheus.MustNewConstMetric(c.connectionErrors, prometheus.CounterValue, float64(stats.ConnectionErrors))
+}
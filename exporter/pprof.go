@@ -0,0 +1,45 @@
+package exporter
+
+import (
+	"errors"
+	"net/http"
+	"net/http/pprof"
+)
+
+// pprofMux serves the profiles of the exporter under /debug/pprof
+func pprofMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
+
+// initPprof serves the profiles on the exporter's http-server or on a separate one if the pprof address is set
+func (e *RethinkdbExporter) initPprof() {
+	if !e.opts.EnablePprof {
+		return
+	}
+	handler := e.requireToken(pprofMux())
+	if e.opts.PprofAddress == "" {
+		e.mux.Handle("/debug/pprof/", handler)
+		return
+	}
+	if e.opts.AccessLog {
+		handler = e.accessLog(handler)
+	}
+	e.pprofServer = e.newServer(e.opts.PprofAddress, handler)
+	// the profiles are written for as long as requested
+	e.pprofServer.WriteTimeout = 0
+}
+
+// listenAndServePprof runs the separate http-server of the profiles until it is shut down
+func (e *RethinkdbExporter) listenAndServePprof() {
+	e.log.Info("serving pprof", "address", e.opts.PprofAddress)
+	err := e.pprofServer.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		e.log.Error("failed to serve pprof", "error", err)
+	}
+}
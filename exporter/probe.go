@@ -0,0 +1,118 @@
+package exporter
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rethinkdb/prometheus-exporter/dbconnector"
+)
+
+const defaultProbeModule = "default"
+
+// ProbeModule defines how the exporter connects to the targets probed with the module
+type ProbeModule struct {
+	// Username to auth in the rethinkdb
+	Username string
+	// Password to auth in the rethinkdb
+	Password string
+	// TLSConfig enables encryption on the connection if not nil
+	TLSConfig *tls.Config
+	// SessionOptions tunes the connection, the pool size is ignored as probes need one connection
+	SessionOptions dbconnector.SessionOptions
+	// TargetFilter limits the targets probed with the module to the addresses matching the regular expression
+	// if it is not empty, it has to match the whole address
+	TargetFilter string
+}
+
+// targetFilter compiles the target filter of the module, all targets are allowed if it is nil
+func (m ProbeModule) targetFilter() (*regexp.Regexp, error) {
+	if m.TargetFilter == "" {
+		return nil, nil
+	}
+	filter, err := regexp.Compile("^(?:" + m.TargetFilter + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid probe target filter: %w", err)
+	}
+	return filter, nil
+}
+
+// validateProbeModules checks that the target filters of the probe modules are valid regular expressions
+func validateProbeModules(modules map[string]ProbeModule) error {
+	for name, module := range modules {
+		_, err := module.targetFilter()
+		if err != nil {
+			return fmt.Errorf("module %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// probeHandler collects metrics of the rethinkdb cluster given in the target parameter,
+// connecting to it with parameters of the module given in the module parameter.
+// Probing is disabled if no modules are configured, the credentials of the main connection are never used.
+func (e *RethinkdbExporter) probeHandler(w http.ResponseWriter, r *http.Request) {
+	opts := e.current().opts
+	if len(opts.ProbeModules) == 0 {
+		http.Error(w, "probing is disabled, no probe modules are configured", http.StatusNotFound)
+		return
+	}
+	target := r.URL.Query().Get("target")
+	if target == "" {
+		http.Error(w, "target parameter is missing", http.StatusBadRequest)
+		return
+	}
+	moduleName := r.URL.Query().Get("module")
+	if moduleName == "" {
+		moduleName = defaultProbeModule
+	}
+	// the probe scrapes only the target
+	opts.Clusters = nil
+	opts.ClusterName = ""
+	opts.StreamStats = false
+	module, ok := opts.ProbeModules[moduleName]
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown module %q", moduleName), http.StatusBadRequest)
+		return
+	}
+	// the filter is validated with the options
+	targets, _ := module.targetFilter()
+	if targets != nil && !targets.MatchString(target) {
+		http.Error(w, fmt.Sprintf("target %q is not allowed for module %q", target, moduleName), http.StatusForbidden)
+		return
+	}
+
+	log := e.log.With("target", target, "module", moduleName)
+	sessionOpts := module.SessionOptions
+	sessionOpts.PoolSize = 1
+	sessionOpts.InitialPoolSize = 0
+	rconn := dbconnector.ConnectRethinkDB(log, []string{target}, module.Username, module.Password, module.TLSConfig, sessionOpts)
+	defer func() {
+		err := rconn.Close()
+		if err != nil {
+			log.Warn("failed to close rethinkdb connection", "error", err)
+		}
+	}()
+
+	probe := newCollector(log, rconn, opts)
+
+	registry := prometheus.NewRegistry()
+	err := registry.Register(requestCollector{RethinkdbExporter: probe, ctx: r.Context()})
+	if err != nil {
+		log.Error("failed to register metrics", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	selfRegistry := prometheus.NewRegistry()
+	err = selfRegistry.Register(probe.self)
+	if err != nil {
+		log.Error("failed to register exporter metrics", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// the exporter's metrics are gathered after the probe so they describe it
+	e.gathererHandler(prometheus.Gatherers{registry, selfRegistry}).ServeHTTP(w, r)
+}
@@ -0,0 +1,65 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// defaultPushJob is the job label of the pushed metrics if it is not configured
+const defaultPushJob = "rethinkdb_exporter"
+
+// PushgatewayOptions defines pushing of the metrics to a Pushgateway
+type PushgatewayOptions struct {
+	// URL of the Pushgateway, the metrics are not pushed if it is empty
+	URL string
+	// Job is the job label of the pushed metrics, "rethinkdb_exporter" if it is empty
+	Job string
+	// Grouping are the grouping labels of the pushed metrics besides the job
+	Grouping map[string]string
+	// Interval of pushing the metrics
+	Interval time.Duration
+}
+
+// pushgatewayOutput replaces the metrics of its group on the Pushgateway with every push
+type pushgatewayOutput struct {
+	opts PushgatewayOptions
+}
+
+func newPushgatewayOutput(opts PushgatewayOptions) (*pushgatewayOutput, error) {
+	u, err := url.Parse(opts.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pushgateway url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("pushgateway url must be a http url, got scheme %q", u.Scheme)
+	}
+	if opts.Interval <= 0 {
+		return nil, fmt.Errorf("pushgateway push interval must be positive, got %s", opts.Interval)
+	}
+	if opts.Job == "" {
+		opts.Job = defaultPushJob
+	}
+	return &pushgatewayOutput{opts: opts}, nil
+}
+
+func (o *pushgatewayOutput) push(ctx context.Context, families []*dto.MetricFamily) error {
+	pusher := push.New(o.opts.URL, o.opts.Job).
+		Gatherer(prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+			return families, nil
+		}))
+	for name, value := range o.opts.Grouping {
+		pusher = pusher.Grouping(name, value)
+	}
+	err := pusher.PushContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to push to pushgateway: %w", err)
+	}
+	return nil
+}
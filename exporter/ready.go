@@ -0,0 +1,68 @@
+package exporter
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// scrapeHealth tracks results of the scrapes of the cluster for the readiness check
+type scrapeHealth struct {
+	m           sync.Mutex
+	lastSuccess time.Time
+	failingFrom time.Time
+}
+
+func (h *scrapeHealth) record(up bool) {
+	h.m.Lock()
+	defer h.m.Unlock()
+	now := time.Now()
+	if up {
+		h.lastSuccess = now
+		h.failingFrom = time.Time{}
+		return
+	}
+	if h.failingFrom.IsZero() {
+		h.failingFrom = now
+	}
+}
+
+// failing returns whether the last scrape failed
+func (h *scrapeHealth) failing() bool {
+	h.m.Lock()
+	defer h.m.Unlock()
+	return !h.failingFrom.IsZero()
+}
+
+// failingFor returns how long the scrapes have been failing, zero if the last one succeeded
+func (h *scrapeHealth) failingFor() time.Duration {
+	h.m.Lock()
+	defer h.m.Unlock()
+	if h.failingFrom.IsZero() {
+		return 0
+	}
+	return time.Since(h.failingFrom)
+}
+
+// readyHandler reports the exporter as not ready if it is not connected to any of the clusters
+// or the scrapes of any of them have been failing for longer than the grace period
+func (e *RethinkdbExporter) readyHandler(w http.ResponseWriter, r *http.Request) {
+	c := e.current()
+	for _, cluster := range append([]*RethinkdbExporter{c}, c.clusters...) {
+		name := "rethinkdb"
+		if cluster.opts.ClusterName != "" {
+			name = fmt.Sprintf("rethinkdb cluster %s", cluster.opts.ClusterName)
+		}
+		if !cluster.rconn.IsConnected() {
+			http.Error(w, fmt.Sprintf("not connected to %s", name), http.StatusServiceUnavailable)
+			return
+		}
+		if failing := cluster.health.failingFor(); failing > c.opts.ReadyGracePeriod {
+			http.Error(w, fmt.Sprintf("scrapes of %s have been failing for %s", name, failing.Round(time.Second)), http.StatusServiceUnavailable)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = fmt.Fprintf(w, "OK")
+}
@@ -0,0 +1,144 @@
+package exporter
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	r "gopkg.in/rethinkdb/rethinkdb-go.v6"
+)
+
+// current returns the collector configured by the last Reconfigure, the exporter itself if it was never called
+func (e *RethinkdbExporter) current() *RethinkdbExporter {
+	if c := e.active.Load(); c != nil {
+		return c
+	}
+	return e
+}
+
+// acquire returns the collector configured by the last Reconfigure,
+// its connection is not reported as released until the returned release function is called
+func (e *RethinkdbExporter) acquire() (*RethinkdbExporter, func()) {
+	for {
+		c := e.current()
+		c.inUse.RLock()
+		// the collector may have been replaced meanwhile, the following scrapes must not use its connection
+		if e.current() == c {
+			return c, c.inUse.RUnlock
+		}
+		c.inUse.RUnlock()
+	}
+}
+
+// Reconfigure replaces the connection and the options used by the following scrapes and probes.
+// Options of the http-server, the background collection and the exporter's own metrics stay unchanged.
+// The returned channel is closed once the collections still using the replaced connection are finished,
+// so it can be closed without failing them.
+func (e *RethinkdbExporter) Reconfigure(rconn r.QueryExecutor, opts Options) (<-chan struct{}, error) {
+	err := validateCollectors(opts.Collectors)
+	if err != nil {
+		return nil, err
+	}
+	err = validateProbeModules(opts.ProbeModules)
+	if err != nil {
+		return nil, err
+	}
+	err = validateNamespace(opts.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	err = validateLegacyNames(opts.LegacyNames)
+	if err != nil {
+		return nil, err
+	}
+	err = validateFilters(opts)
+	if err != nil {
+		return nil, err
+	}
+	err = validateExactCountTables(opts.ExactCountTables)
+	if err != nil {
+		return nil, err
+	}
+	err = validateLabels(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	c := newCollector(e.log, rconn, opts)
+	c.self = e.self
+	previous := e.current()
+	for _, cluster := range c.clusters {
+		cluster.self = e.clusterSelf(previous, cluster.opts.ClusterName)
+	}
+	err = prometheus.NewRegistry().Register(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register metrics: %w", err)
+	}
+
+	e.active.Store(c)
+	// changefeeds of the replaced connection are closed, the new ones are opened on the next scrape
+	previous.stopStreams()
+	released := make(chan struct{})
+	go func() {
+		previous.inUse.Lock()
+		previous.inUse.Unlock()
+		close(released)
+	}()
+	// collectors may have been disabled, their last results are dropped
+	e.self.setClientCertificate(opts.ClientCertificate)
+	for _, cluster := range append([]*RethinkdbExporter{c}, c.clusters...) {
+		cluster.self.resetCollectors()
+		// the new connection starts with the circuit breaker closed
+		cluster.self.breakerOpen.Set(0)
+	}
+	return released, nil
+}
+
+// Reload reloads the configuration with the OnReload function of the options
+// and reports the result in the exporter's own metrics
+func (e *RethinkdbExporter) Reload() error {
+	if e.opts.OnReload == nil {
+		return errors.New("reload is not configured")
+	}
+
+	e.reloadMutex.Lock()
+	defer e.reloadMutex.Unlock()
+
+	err := e.opts.OnReload()
+	if err != nil {
+		e.log.Error("failed to reload config", "error", err)
+		e.self.configReloadSuccess.Set(0)
+		return err
+	}
+
+	e.log.Info("config reloaded")
+	e.self.configReloadSuccess.Set(1)
+	e.self.configReloadTime.SetToCurrentTime()
+	return nil
+}
+
+// ReloadCredentials reloads the configuration after the credentials of the connection changed
+func (e *RethinkdbExporter) ReloadCredentials() error {
+	err := e.Reload()
+	if err != nil {
+		return err
+	}
+	e.self.credentialsReloadTime.SetToCurrentTime()
+	return nil
+}
+
+func (e *RethinkdbExporter) reloadHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodPut {
+		w.Header().Set("Allow", "POST, PUT")
+		http.Error(w, "only POST or PUT requests allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	err := e.Reload()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to reload config: %s", err), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = fmt.Fprintf(w, "OK")
+}
@@ -0,0 +1,318 @@
+package exporter
+
+import (
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// selfMetrics describes the exporter itself instead of the rethinkdb.
+// They can be served on a separate path from the rethinkdb metrics.
+type selfMetrics struct {
+	*clusterSelfMetrics
+
+	scrapesRejected *prometheus.CounterVec
+
+	pushErrors *prometheus.CounterVec
+
+	configReloadSuccess prometheus.Gauge
+	configReloadTime    prometheus.Gauge
+
+	credentialsReloadTime prometheus.Gauge
+	clientCertExpiry      *prometheus.GaugeVec
+}
+
+// clusterSelfMetrics describes scraping of one cluster,
+// they are labeled with the cluster name when the exporter is given one
+type clusterSelfMetrics struct {
+	scrapeDuration prometheus.Histogram
+	scrapeLatency  prometheus.Gauge
+	scrapeErrors   *prometheus.CounterVec
+
+	scrapeTimedOut prometheus.Gauge
+
+	breakerOpen  prometheus.Gauge
+	breakerTrips prometheus.Counter
+
+	collectorSuccess  *prometheus.GaugeVec
+	collectorDuration *prometheus.GaugeVec
+
+	queryDuration   *prometheus.HistogramVec
+	queriesInFlight prometheus.Gauge
+	queryRetries    *prometheus.CounterVec
+
+	tableInfoQueueDuration prometheus.Histogram
+	tableInfoSkipped       prometheus.Counter
+}
+
+// reasons of rejecting scrapes
+const (
+	// rejectedMaxRequests is over the limit of concurrent requests, rejected with 503
+	rejectedMaxRequests = "max_requests"
+	// rejectedMinInterval is sooner than the min scrape interval after the last scrape, rejected with 429
+	rejectedMinInterval = "min_scrape_interval"
+)
+
+var scrapeRejectReasons = []string{rejectedMaxRequests, rejectedMinInterval}
+
+func newSelfMetrics(opts Options) *selfMetrics {
+	ns := metricsNamespace(opts)
+	s := &selfMetrics{
+		clusterSelfMetrics: newClusterSelfMetrics(opts),
+		scrapesRejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Subsystem: exporterSubsystem,
+			Name:      "scrapes_rejected_total",
+			Help:      "Total number of scrapes rejected by reason",
+		}, []string{"reason"}),
+		pushErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Subsystem: exporterSubsystem,
+			Name:      "push_errors_total",
+			Help:      "Total number of failed pushes of the metrics by output",
+		}, []string{"output"}),
+		configReloadSuccess: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: ns,
+			Subsystem: exporterSubsystem,
+			Name:      "config_last_reload_successful",
+			Help:      "Whether the last configuration reload attempt was successful",
+		}),
+		configReloadTime: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: ns,
+			Subsystem: exporterSubsystem,
+			Name:      "config_last_reload_success_timestamp_seconds",
+			Help:      "Timestamp of the last successful configuration reload",
+		}),
+		credentialsReloadTime: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: ns,
+			Subsystem: exporterSubsystem,
+			Name:      "credentials_last_reload_timestamp_seconds",
+			Help:      "Timestamp of the last successful reconnection with changed credentials",
+		}),
+		clientCertExpiry: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Subsystem: exporterSubsystem,
+			Name:      "client_certificate_expiry_timestamp_seconds",
+			Help:      "Timestamp when the client certificate of the rethinkdb connection expires",
+		}, []string{"subject"}),
+	}
+	s.configReloadSuccess.Set(1)
+	s.configReloadTime.SetToCurrentTime()
+	s.credentialsReloadTime.SetToCurrentTime()
+	s.setClientCertificate(opts.ClientCertificate)
+	for _, reason := range scrapeRejectReasons {
+		s.scrapesRejected.WithLabelValues(reason)
+	}
+	return s
+}
+
+func newClusterSelfMetrics(opts Options) *clusterSelfMetrics {
+	buckets := opts.ScrapeDurationBuckets
+	if len(buckets) == 0 {
+		buckets = prometheus.DefBuckets
+	}
+
+	var labels prometheus.Labels
+	if opts.ClusterName != "" {
+		labels = prometheus.Labels{clusterLabel(opts): opts.ClusterName}
+	}
+
+	ns := metricsNamespace(opts)
+	s := &clusterSelfMetrics{
+		scrapeDuration: prometheus.NewHistogram(withNativeHistogram(prometheus.HistogramOpts{
+			Namespace:   ns,
+			Subsystem:   exporterSubsystem,
+			Name:        "scrape_duration_seconds",
+			Help:        "Duration of collecting scrapes",
+			Buckets:     buckets,
+			ConstLabels: labels,
+		}, opts.NativeHistograms)),
+		scrapeErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   ns,
+			Subsystem:   exporterSubsystem,
+			Name:        "scrape_errors_total",
+			Help:        "Total number of errors while collecting scrapes by type",
+			ConstLabels: labels,
+		}, []string{"type"}),
+		scrapeTimedOut: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   ns,
+			Subsystem:   exporterSubsystem,
+			Name:        "scrape_timed_out",
+			Help:        "Whether the last scrape was interrupted by the scrape timeout",
+			ConstLabels: labels,
+		}),
+		breakerOpen: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   ns,
+			Subsystem:   exporterSubsystem,
+			Name:        "circuit_breaker_open",
+			Help:        "Whether the circuit breaker is open, so scrapes don't query the cluster",
+			ConstLabels: labels,
+		}),
+		breakerTrips: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   ns,
+			Subsystem:   exporterSubsystem,
+			Name:        "circuit_breaker_trips_total",
+			Help:        "Total number of times the circuit breaker was opened after consecutive failed scrapes",
+			ConstLabels: labels,
+		}),
+		collectorSuccess: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace:   ns,
+			Subsystem:   exporterSubsystem,
+			Name:        "collector_success",
+			Help:        "Whether the collector succeeded during the last scrape",
+			ConstLabels: labels,
+		}, []string{"collector"}),
+		collectorDuration: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace:   ns,
+			Subsystem:   exporterSubsystem,
+			Name:        "collector_duration_seconds",
+			Help:        "Duration of the collector during the last scrape",
+			ConstLabels: labels,
+		}, []string{"collector"}),
+		queryDuration: prometheus.NewHistogramVec(withNativeHistogram(prometheus.HistogramOpts{
+			Namespace:   ns,
+			Subsystem:   exporterSubsystem,
+			Name:        "query_duration_seconds",
+			Help:        "Duration of queries to the rethinkdb",
+			Buckets:     prometheus.DefBuckets,
+			ConstLabels: labels,
+		}, opts.NativeHistograms), []string{"query"}),
+		queriesInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   ns,
+			Subsystem:   exporterSubsystem,
+			Name:        "queries_in_flight",
+			Help:        "Number of queries to the rethinkdb in progress, they share the connections of the pool",
+			ConstLabels: labels,
+		}),
+		queryRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   ns,
+			Subsystem:   exporterSubsystem,
+			Name:        "query_retries_total",
+			Help:        "Total number of queries to the rethinkdb retried after connection errors",
+			ConstLabels: labels,
+		}, []string{"query"}),
+		tableInfoQueueDuration: prometheus.NewHistogram(withNativeHistogram(prometheus.HistogramOpts{
+			Namespace:   ns,
+			Subsystem:   exporterSubsystem,
+			Name:        "table_info_queue_duration_seconds",
+			Help:        "Time queries of the table rows count estimates wait for the concurrency limit",
+			Buckets:     prometheus.DefBuckets,
+			ConstLabels: labels,
+		}, opts.NativeHistograms)),
+		tableInfoSkipped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   ns,
+			Subsystem:   exporterSubsystem,
+			Name:        "table_info_skipped_total",
+			Help:        "Total number of table rows count estimates skipped after the table info timeout",
+			ConstLabels: labels,
+		}),
+	}
+	for _, t := range scrapeErrorTypes {
+		s.scrapeErrors.WithLabelValues(t)
+	}
+	if opts.LegacyScrapeLatency {
+		s.scrapeLatency = prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "scrape_latency",
+			Help:        "Latency of collecting scrape",
+			ConstLabels: labels,
+		})
+	}
+	return s
+}
+
+// Describe sends metrics descriptions to the prometheus chan
+func (s *selfMetrics) Describe(ch chan<- *prometheus.Desc) {
+	s.clusterSelfMetrics.Describe(ch)
+	s.scrapesRejected.Describe(ch)
+	s.pushErrors.Describe(ch)
+	s.configReloadSuccess.Describe(ch)
+	s.configReloadTime.Describe(ch)
+	s.credentialsReloadTime.Describe(ch)
+	s.clientCertExpiry.Describe(ch)
+}
+
+// Collect sends metrics values to the prometheus chan
+func (s *selfMetrics) Collect(ch chan<- prometheus.Metric) {
+	s.clusterSelfMetrics.Collect(ch)
+	s.scrapesRejected.Collect(ch)
+	s.pushErrors.Collect(ch)
+	s.configReloadSuccess.Collect(ch)
+	s.configReloadTime.Collect(ch)
+	s.credentialsReloadTime.Collect(ch)
+	s.clientCertExpiry.Collect(ch)
+}
+
+// Describe sends metrics descriptions to the prometheus chan
+func (s *clusterSelfMetrics) Describe(ch chan<- *prometheus.Desc) {
+	s.scrapeDuration.Describe(ch)
+	if s.scrapeLatency != nil {
+		s.scrapeLatency.Describe(ch)
+	}
+	s.scrapeErrors.Describe(ch)
+	s.scrapeTimedOut.Describe(ch)
+	s.breakerOpen.Describe(ch)
+	s.breakerTrips.Describe(ch)
+	s.collectorSuccess.Describe(ch)
+	s.collectorDuration.Describe(ch)
+	s.queryDuration.Describe(ch)
+	s.queriesInFlight.Describe(ch)
+	s.queryRetries.Describe(ch)
+	s.tableInfoQueueDuration.Describe(ch)
+	s.tableInfoSkipped.Describe(ch)
+}
+
+// Collect sends metrics values to the prometheus chan
+func (s *clusterSelfMetrics) Collect(ch chan<- prometheus.Metric) {
+	s.scrapeDuration.Collect(ch)
+	if s.scrapeLatency != nil {
+		s.scrapeLatency.Collect(ch)
+	}
+	s.scrapeErrors.Collect(ch)
+	s.scrapeTimedOut.Collect(ch)
+	s.breakerOpen.Collect(ch)
+	s.breakerTrips.Collect(ch)
+	s.collectorSuccess.Collect(ch)
+	s.collectorDuration.Collect(ch)
+	s.queryDuration.Collect(ch)
+	s.queriesInFlight.Collect(ch)
+	s.queryRetries.Collect(ch)
+	s.tableInfoQueueDuration.Collect(ch)
+	s.tableInfoSkipped.Collect(ch)
+}
+
+// resetCollectors drops the collector results, collectors removed by a reload don't keep their last values
+func (s *clusterSelfMetrics) resetCollectors() {
+	s.collectorSuccess.Reset()
+	s.collectorDuration.Reset()
+}
+
+// setClientCertificate exports expiry of the client certificate replacing the previous one
+func (s *selfMetrics) setClientCertificate(cert *x509.Certificate) {
+	s.clientCertExpiry.Reset()
+	if cert != nil {
+		s.clientCertExpiry.WithLabelValues(cert.Subject.String()).Set(float64(cert.NotAfter.Unix()))
+	}
+}
+
+// withNativeHistogram enables native histogram in addition to the classic buckets,
+// the scraper chooses which of them to ingest
+func withNativeHistogram(opts prometheus.HistogramOpts, enabled bool) prometheus.HistogramOpts {
+	if enabled {
+		opts.NativeHistogramBucketFactor = 1.1
+		opts.NativeHistogramMaxBucketNumber = 100
+		opts.NativeHistogramMinResetDuration = time.Hour
+	}
+	return opts
+}
+
+// validateBuckets checks that upper bounds of histogram buckets are in increasing order
+func validateBuckets(buckets []float64) error {
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i] <= buckets[i-1] {
+			return fmt.Errorf("histogram buckets must be in increasing order: %v", buckets)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	r "gopkg.in/rethinkdb/rethinkdb-go.v6"
+)
+
+func init() {
+	registerCollector("server_status", true, newServerStatusCollector)
+}
+
+// serverStatusCollector collects process information of the servers from the rethinkdb.server_status system table
+type serverStatusCollector struct {
+	e *RethinkdbExporter
+
+	info           *prometheus.Desc
+	startTime      *prometheus.Desc
+	uptime         *prometheus.Desc
+	cacheSizeBytes *prometheus.Desc
+	processID      *prometheus.Desc
+
+	versionSkew *prometheus.Desc
+}
+
+func newServerStatusCollector(e *RethinkdbExporter) collector {
+	return &serverStatusCollector{
+		e: e,
+		info: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "server", "info"),
+			"Information about the server process, always 1",
+			e.withServerID("server", "version")...),
+		startTime: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "server", "start_time_seconds"),
+			"Start time of the server process since unix epoch in seconds",
+			e.withServerID("server")...),
+		uptime: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "server", "uptime_seconds"),
+			"Time since the server process started",
+			e.withServerID("server")...),
+		cacheSizeBytes: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "server", "cache_size_bytes"),
+			"Size of the page cache of the server",
+			e.withServerID("server")...),
+		processID: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "server", "process_id"),
+			"Process id of the server",
+			e.withServerID("server")...),
+		versionSkew: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "cluster", "version_skew"),
+			"Whether the servers of the cluster run different rethinkdb versions"),
+	}
+}
+
+type serverStatus struct {
+	ID      string `rethinkdb:"id"`
+	Name    string `rethinkdb:"name"`
+	Process struct {
+		CacheSizeMB float64   `rethinkdb:"cache_size_mb"`
+		PID         float64   `rethinkdb:"pid"`
+		TimeStarted time.Time `rethinkdb:"time_started"`
+		Version     string    `rethinkdb:"version"`
+	} `rethinkdb:"process"`
+}
+
+// Describe sends metrics descriptions to the prometheus chan
+func (c *serverStatusCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.info
+	ch <- c.startTime
+	ch <- c.uptime
+	ch <- c.cacheSizeBytes
+	ch <- c.processID
+
+	ch <- c.versionSkew
+}
+
+// Update sends collected metrics values to the prometheus chan.
+// The version skew includes the servers dropped by the filters.
+func (c *serverStatusCollector) Update(ctx context.Context, ch chan<- prometheus.Metric) error {
+	var statuses []serverStatus
+	err := c.e.readAll(ctx, r.ServerStatusSystemTable, c.e.systemTable(r.ServerStatusSystemTable), &statuses)
+	if err != nil {
+		return fmt.Errorf("failed to query system server_status table: %w", err)
+	}
+
+	versions := make(map[string]struct{})
+	for _, status := range statuses {
+		versions[versionNumber(status.Process.Version)] = struct{}{}
+		if !c.e.filters.serverMatches(status.Name) {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.info, prometheus.GaugeValue, 1, c.e.withServerIDValue(status.ID, status.Name, status.Process.Version)...)
+		ch <- prometheus.MustNewConstMetric(c.startTime, prometheus.GaugeValue, float64(status.Process.TimeStarted.Unix()), c.e.withServerIDValue(status.ID, status.Name)...)
+		ch <- prometheus.MustNewConstMetric(c.uptime, prometheus.GaugeValue, time.Since(status.Process.TimeStarted).Seconds(), c.e.withServerIDValue(status.ID, status.Name)...)
+		ch <- prometheus.MustNewConstMetric(c.cacheSizeBytes, prometheus.GaugeValue, status.Process.CacheSizeMB*1024*1024, c.e.withServerIDValue(status.ID, status.Name)...)
+		ch <- prometheus.MustNewConstMetric(c.processID, prometheus.GaugeValue, status.Process.PID, c.e.withServerIDValue(status.ID, status.Name)...)
+	}
+	ch <- prometheus.MustNewConstMetric(c.versionSkew, prometheus.GaugeValue, boolToFloat(len(versions) > 1))
+	return nil
+}
+
+// versionNumber returns the version number of the process version like "rethinkdb 2.4.4~0bookworm (GCC 12.2.0)",
+// so the same version built for different distributions or by different compilers isn't a skew
+func versionNumber(version string) string {
+	fields := strings.Fields(version)
+	if len(fields) < 2 {
+		return version
+	}
+	number, _, _ := strings.Cut(fields[1], "~")
+	return number
+}
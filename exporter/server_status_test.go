@@ -0,0 +1,24 @@
+package exporter
+
+import "testing"
+
+func TestVersionNumber(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{version: "rethinkdb 2.4.4~0bookworm (GCC 12.2.0)", want: "2.4.4"},
+		{version: "rethinkdb 2.4.4~0jammy (GCC 11.4.0)", want: "2.4.4"},
+		{version: "rethinkdb 2.4.3 (CLANG 14.0.0)", want: "2.4.3"},
+		{version: "rethinkdb 2.3.7~0xenial", want: "2.3.7"},
+		{version: "rethinkdb", want: "rethinkdb"},
+		{version: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			if got := versionNumber(tt.version); got != tt.want {
+				t.Errorf("versionNumber(%q) = %q, want %q", tt.version, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,598 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/errgroup"
+	r "gopkg.in/rethinkdb/rethinkdb-go.v6"
+	"gopkg.in/rethinkdb/rethinkdb-go.v6/encoding"
+)
+
+func init() {
+	registerCollector("stats", true, newStatsCollector)
+}
+
+// statsCollector collects metrics from the rethinkdb.stats system table
+type statsCollector struct {
+	e *RethinkdbExporter
+
+	clusterClientConnections *prometheus.Desc
+	clusterDocsPerSecond     *prometheus.Desc
+
+	serverClientConnections *prometheus.Desc
+	serverQueriesPerSecond  *prometheus.Desc
+	serverDocsPerSecond     *prometheus.Desc
+	serverQueriesTotal      *prometheus.Desc
+	serverDocsTotal         *prometheus.Desc
+
+	tableDocsPerSecond     *prometheus.Desc
+	tableRowsCount         *prometheus.Desc
+	tableShardDocsEstimate *prometheus.Desc
+
+	tableReplicaDocsPerSecond *prometheus.Desc
+	tableReplicaCacheBytes    *prometheus.Desc
+	tableReplicaIO            *prometheus.Desc
+	tableReplicaDataBytes     *prometheus.Desc
+	tableReplicaMetaBytes     *prometheus.Desc
+	tableReplicaGarbageBytes  *prometheus.Desc
+	tableReplicaPreallocBytes *prometheus.Desc
+	tableReplicaDocsTotal     *prometheus.Desc
+	tableReplicaIOTotal       *prometheus.Desc
+}
+
+func newStatsCollector(e *RethinkdbExporter) collector {
+	c := &statsCollector{e: e}
+
+	c.clusterClientConnections = e.newStatsDesc(
+		"cluster_client_connections",
+		"Total number of connections from the cluster")
+	c.clusterDocsPerSecond = e.newStatsDesc(
+		"cluster_docs_per_second",
+		"Total number of reads and writes of documents per second from the cluster",
+		"operation")
+
+	// the server stats are labeled with the role of the server as well
+	serverLabels := func(labels ...string) []string {
+		labels = e.withServerID(labels...)
+		if e.opts.ServerRoleLabel {
+			labels = append(labels, serverRoleLabel)
+		}
+		return labels
+	}
+	c.serverClientConnections = e.newStatsDesc(
+		"server_client_connections",
+		"Number of client connections to the server",
+		serverLabels("server")...)
+	c.serverQueriesPerSecond = e.newStatsDesc(
+		"server_queries_per_second",
+		"Number of queries per second from the server",
+		serverLabels("server")...)
+	c.serverDocsPerSecond = e.newStatsDesc(
+		"server_docs_per_second",
+		"Total number of reads and writes of documents per second from the server",
+		serverLabels("server", "operation")...)
+	c.serverQueriesTotal = e.newStatsDesc(
+		"server_queries_total",
+		"Total number of queries from the server since it started",
+		serverLabels("server")...)
+	c.serverDocsTotal = e.newStatsDesc(
+		"server_docs_total",
+		"Total number of reads and writes of documents from the server since it started",
+		serverLabels("server", "operation")...)
+
+	c.tableDocsPerSecond = e.newStatsDesc(
+		"table_docs_per_second",
+		"Number of reads and writes of documents per second from the table",
+		e.withTableID("db", "table", "operation")...)
+
+	if e.collectTableStats {
+		c.tableRowsCount = e.newStatsDesc(
+			"table_rows_count",
+			"Approximate number of rows in the table",
+			e.withTableID("db", "table")...)
+		if e.opts.CollectShardEstimates {
+			c.tableShardDocsEstimate = e.newDesc(
+				prometheus.BuildFQName(e.namespace, "table", "shard_docs_estimate"),
+				"Approximate number of documents in the shard of the table",
+				e.withTableID("db", "table", "shard")...)
+		}
+	}
+
+	// the table replica stats are labeled with the ids of both the table and the server
+	replicaLabels := func(labels ...string) []string {
+		return e.withServerID(e.withTableID(labels...)...)
+	}
+	c.tableReplicaDocsPerSecond = e.newStatsDesc(
+		"tablereplica_docs_per_second",
+		"Number of reads and writes of documents per second from the table replica",
+		replicaLabels("db", "table", "server", "operation")...)
+	c.tableReplicaCacheBytes = e.newStatsDesc(
+		"tablereplica_cache_bytes",
+		"Table replica cache size in bytes",
+		replicaLabels("db", "table", "server")...)
+	c.tableReplicaIO = e.newStatsDesc(
+		"tablereplica_io",
+		"Table replica reads and writes of bytes per second",
+		replicaLabels("db", "table", "server", "operation")...)
+	c.tableReplicaDataBytes = e.newStatsDesc(
+		"tablereplica_data_bytes",
+		"Table replica size in stored bytes",
+		replicaLabels("db", "table", "server")...)
+	c.tableReplicaMetaBytes = e.newStatsDesc(
+		"tablereplica_metadata_bytes",
+		"Table replica size of metadata in bytes",
+		replicaLabels("db", "table", "server")...)
+	c.tableReplicaGarbageBytes = e.newStatsDesc(
+		"tablereplica_garbage_bytes",
+		"Table replica size of garbage not yet collected in bytes",
+		replicaLabels("db", "table", "server")...)
+	c.tableReplicaPreallocBytes = e.newStatsDesc(
+		"tablereplica_preallocated_bytes",
+		"Table replica size of preallocated but unused disk space in bytes",
+		replicaLabels("db", "table", "server")...)
+	c.tableReplicaDocsTotal = e.newStatsDesc(
+		"tablereplica_docs_total",
+		"Total number of reads and writes of documents from the table replica since the server started",
+		replicaLabels("db", "table", "server", "operation")...)
+	c.tableReplicaIOTotal = e.newStatsDesc(
+		"tablereplica_io_bytes_total",
+		"Total number of bytes read and written by the table replica since the server started",
+		replicaLabels("db", "table", "server", "operation")...)
+
+	return c
+}
+
+// Describe sends metrics descriptions to the prometheus chan
+func (c *statsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.clusterClientConnections
+	ch <- c.clusterDocsPerSecond
+
+	ch <- c.serverClientConnections
+	ch <- c.serverQueriesPerSecond
+	ch <- c.serverDocsPerSecond
+	ch <- c.serverQueriesTotal
+	ch <- c.serverDocsTotal
+
+	ch <- c.tableDocsPerSecond
+	if c.tableRowsCount != nil {
+		ch <- c.tableRowsCount
+	}
+	if c.tableShardDocsEstimate != nil {
+		ch <- c.tableShardDocsEstimate
+	}
+
+	ch <- c.tableReplicaDocsPerSecond
+	ch <- c.tableReplicaCacheBytes
+	ch <- c.tableReplicaIO
+	ch <- c.tableReplicaDataBytes
+	ch <- c.tableReplicaMetaBytes
+	ch <- c.tableReplicaGarbageBytes
+	ch <- c.tableReplicaPreallocBytes
+	ch <- c.tableReplicaDocsTotal
+	ch <- c.tableReplicaIOTotal
+}
+
+// statsPhases are the phases of the collector reported for the stats of every kind, which is the first element of their id
+var statsPhases = map[string]string{
+	"cluster":      "stats_cluster",
+	"server":       "stats_server",
+	"table":        "stats_table",
+	"table_server": "stats_table_replica",
+}
+
+// Update sends collected metrics values to the prometheus chan.
+// The stats of all kinds are read by one query, every kind is reported as a phase of the collector
+// timed from the start until its last stat is processed. The rows count estimates are queried while the stats are read.
+func (c *statsCollector) Update(ctx context.Context, ch chan<- prometheus.Metric) error {
+	start := time.Now()
+
+	// the roles of the servers are queried while the stats are read, the server stats wait for them
+	var (
+		dataServers map[string]bool
+		rolesErr    error
+	)
+	roles := make(chan struct{})
+	go func() {
+		defer close(roles)
+		dataServers, rolesErr = c.dataServers(ctx)
+	}()
+
+	tableInfo := c.newTableInfoQueries(ctx, ch)
+	finished := make(map[string]time.Duration, len(statsPhases))
+	var servers []stat
+	err := c.processStats(ctx, func(stat stat) {
+		kind := stat.ID[0]
+		switch kind {
+		case "cluster":
+			c.processClusterStat(stat, ch)
+		case "server":
+			if c.e.filters.serverMatches(stat.Server) {
+				servers = append(servers, stat)
+			}
+		case "table":
+			if c.e.filters.tableMatches(stat.Database, stat.Table) {
+				c.processTableStat(stat, ch)
+				tableInfo.query(stat)
+			}
+		case "table_server":
+			if c.e.filters.tableMatches(stat.Database, stat.Table) && c.e.filters.serverMatches(stat.Server) {
+				c.processTableServerStat(stat, ch)
+			}
+		}
+		finished[kind] = time.Since(start)
+	})
+
+	<-roles
+	for _, stat := range servers {
+		c.processServerStat(stat, serverRole(dataServers, stat.serverID()), ch)
+	}
+	finished["server"] = time.Since(start)
+
+	for kind, phase := range statsPhases {
+		elapsed, ok := finished[kind]
+		if !ok {
+			elapsed = time.Since(start)
+		}
+		phaseErr := err
+		if kind == "server" {
+			phaseErr = errors.Join(err, rolesErr)
+		}
+		_ = c.e.recordPhase(phase, elapsed, phaseErr)
+	}
+
+	var infoErr error
+	if c.tableRowsCount != nil {
+		infoErr = tableInfo.wait()
+		_ = c.e.recordPhase("stats_table_info", time.Since(start), infoErr)
+	}
+	return errors.Join(err, rolesErr, infoErr)
+}
+
+// statsFields are the fields of the stats which are exported, the others are not sent by the rethinkdb.
+// The fields missing in the stats of some kinds are skipped by pluck.
+var statsFields = []interface{}{"id", "server", "db", "table", map[string]interface{}{
+	"query_engine": []string{"client_connections", "queries_per_sec", "read_docs_per_sec", "written_docs_per_sec",
+		"queries_total", "read_docs_total", "written_docs_total"},
+	"storage_engine": map[string]interface{}{
+		"cache": []string{"in_use_bytes"},
+		"disk": map[string]interface{}{
+			"read_bytes_per_sec":    true,
+			"written_bytes_per_sec": true,
+			"read_bytes_total":      true,
+			"written_bytes_total":   true,
+			"space_usage":           []string{"data_bytes", "metadata_bytes", "garbage_bytes", "preallocated_bytes"},
+		},
+	},
+}}
+
+// statsQuery builds the query of the stats plucking only the exported fields.
+// The stats of the tables and servers are filtered by the rethinkdb as well if it is enabled,
+// the stats of every kind by the filters applying to it.
+func (c *statsCollector) statsQuery() r.Term {
+	query := c.e.systemTable(r.StatsSystemTable)
+	if c.e.opts.ServerSideFilters {
+		var tableConditions, serverConditions []interface{}
+		for _, condition := range c.e.filters.dbs.conditions(r.Row.Field("db")) {
+			tableConditions = append(tableConditions, condition)
+		}
+		for _, condition := range c.e.filters.tables.conditions(r.Row.Field("table")) {
+			tableConditions = append(tableConditions, condition)
+		}
+		for _, condition := range c.e.filters.servers.conditions(r.Row.Field("server")) {
+			serverConditions = append(serverConditions, condition)
+		}
+		if len(tableConditions) > 0 || len(serverConditions) > 0 {
+			kind := r.Row.Field("id").Nth(0)
+			query = query.Filter(r.Or(
+				// the cluster stats are not filtered
+				kind.Eq("cluster"),
+				kind.Eq("server").And(append([]interface{}{true}, serverConditions...)...),
+				kind.Eq("table").And(append([]interface{}{true}, tableConditions...)...),
+				kind.Eq("table_server").And(append(append([]interface{}{true}, tableConditions...), serverConditions...)...),
+			))
+		}
+	}
+	return query.Pluck(statsFields...)
+}
+
+// processStats streams the stats of all kinds
+func (c *statsCollector) processStats(ctx context.Context, process func(stat stat)) error {
+	if docs, ok := c.e.streamed(r.StatsSystemTable); ok {
+		return c.processStreamedStats(docs, process)
+	}
+
+	defer c.e.startQuery(r.StatsSystemTable)()
+
+	// the query is not retried once the stats are streamed, so they are not processed twice
+	var cur *r.Cursor
+	err := c.e.withRetries(ctx, r.StatsSystemTable, func() error {
+		var err error
+		cur, err = c.statsQuery().Run(c.e.rconn, c.e.runOpts(ctx))
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to query system stats table: %w", err)
+	}
+	defer func() {
+		err := cur.Close()
+		if err != nil {
+			c.e.log.Warn("error while closing cursor", "error", err)
+		}
+	}()
+
+	for {
+		// the stats of the kinds have different fields, so every one is decoded into a new value
+		var stat stat
+		if !cur.Next(&stat) {
+			break
+		}
+		if len(stat.ID) > 0 {
+			process(stat)
+		}
+	}
+	if cur.Err() != nil {
+		return fmt.Errorf("query error from cursor of stats: %w", cur.Err())
+	}
+	return nil
+}
+
+// processStreamedStats processes the stats from the documents kept up to date by the changefeed
+func (c *statsCollector) processStreamedStats(docs []interface{}, process func(stat stat)) error {
+	for _, doc := range docs {
+		var stat stat
+		err := encoding.Decode(&stat, doc)
+		if err != nil {
+			return fmt.Errorf("failed to decode streamed stats: %w", err)
+		}
+		if len(stat.ID) > 0 {
+			process(stat)
+		}
+	}
+	return nil
+}
+
+// serverValues returns the label values of the per-server stat followed by the server id if it is enabled
+func (c *statsCollector) serverValues(stat stat, values ...string) []string {
+	return c.e.withServerIDValue(stat.serverID(), values...)
+}
+
+// serverStatValues returns the label values of the server stat followed by the server id and role if they are enabled
+func (c *statsCollector) serverStatValues(stat stat, role string, values ...string) []string {
+	values = c.serverValues(stat, values...)
+	if c.e.opts.ServerRoleLabel {
+		values = append(values, role)
+	}
+	return values
+}
+
+// tableValues returns the label values of the per-table stat followed by the table id if it is enabled
+func (c *statsCollector) tableValues(stat stat, values ...string) []string {
+	return c.e.withTableIDValue(stat.tableID(), values...)
+}
+
+// replicaValues returns the label values of the table replica stat followed by the table and server ids if they are enabled
+func (c *statsCollector) replicaValues(stat stat, values ...string) []string {
+	return c.e.withServerIDValue(stat.serverID(), c.tableValues(stat, values...)...)
+}
+
+type stat struct {
+	ID            []string      `rethinkdb:"id"`
+	Server        string        `rethinkdb:"server"`
+	Database      string        `rethinkdb:"db"`
+	Table         string        `rethinkdb:"table"`
+	QueryEngine   queryEngine   `rethinkdb:"query_engine"`
+	StorageEngine storageEngine `rethinkdb:"storage_engine"`
+}
+
+// serverID returns the uuid of the server of the server and table replica stats, which is the last element of their id
+func (s stat) serverID() string {
+	if len(s.ID) == 0 {
+		return ""
+	}
+	return s.ID[len(s.ID)-1]
+}
+
+// tableID returns the uuid of the table of the table and table replica stats, which is the second element of their id
+func (s stat) tableID() string {
+	if len(s.ID) < 2 {
+		return ""
+	}
+	return s.ID[1]
+}
+
+type queryEngine struct {
+	ClientConnections float64 `rethinkdb:"client_connections"`
+	QPS               float64 `rethinkdb:"queries_per_sec"`
+	ReadDocsPerSec    float64 `rethinkdb:"read_docs_per_sec"`
+	WrittenDocsPerSec float64 `rethinkdb:"written_docs_per_sec"`
+
+	// totals are reported for servers and table replicas only
+	QueriesTotal     float64 `rethinkdb:"queries_total"`
+	ReadDocsTotal    float64 `rethinkdb:"read_docs_total"`
+	WrittenDocsTotal float64 `rethinkdb:"written_docs_total"`
+}
+
+type storageEngine struct {
+	Cache struct {
+		InUseBytes float64 `rethinkdb:"in_use_bytes"`
+	} `rethinkdb:"cache"`
+	Disk struct {
+		ReadBytesPerSec    float64 `rethinkdb:"read_bytes_per_sec"`
+		WrittenBytesPerSec float64 `rethinkdb:"written_bytes_per_sec"`
+		ReadBytesTotal     float64 `rethinkdb:"read_bytes_total"`
+		WrittenBytesTotal  float64 `rethinkdb:"written_bytes_total"`
+		SpaceUsage         struct {
+			DataBytes         float64 `rethinkdb:"data_bytes"`
+			MetadataBytes     float64 `rethinkdb:"metadata_bytes"`
+			GarbageBytes      float64 `rethinkdb:"garbage_bytes"`
+			PreallocatedBytes float64 `rethinkdb:"preallocated_bytes"`
+		} `rethinkdb:"space_usage"`
+	} `rethinkdb:"disk"`
+}
+
+type info struct {
+	DocCountEstimates []float64 `rethinkdb:"doc_count_estimates"`
+}
+
+func (c *statsCollector) processClusterStat(stat stat, ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(c.clusterClientConnections, prometheus.GaugeValue, stat.QueryEngine.ClientConnections)
+
+	ch <- prometheus.MustNewConstMetric(c.clusterDocsPerSecond, prometheus.GaugeValue, stat.QueryEngine.ReadDocsPerSec, readOperation)
+	ch <- prometheus.MustNewConstMetric(c.clusterDocsPerSecond, prometheus.GaugeValue, stat.QueryEngine.WrittenDocsPerSec, writtenOperation)
+}
+
+func (c *statsCollector) processServerStat(stat stat, role string, ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(c.serverClientConnections, prometheus.GaugeValue, stat.QueryEngine.ClientConnections, c.serverStatValues(stat, role, stat.Server)...)
+
+	ch <- prometheus.MustNewConstMetric(c.serverDocsPerSecond, prometheus.GaugeValue, stat.QueryEngine.ReadDocsPerSec, c.serverStatValues(stat, role, stat.Server, readOperation)...)
+	ch <- prometheus.MustNewConstMetric(c.serverDocsPerSecond, prometheus.GaugeValue, stat.QueryEngine.WrittenDocsPerSec, c.serverStatValues(stat, role, stat.Server, writtenOperation)...)
+
+	ch <- prometheus.MustNewConstMetric(c.serverQueriesPerSecond, prometheus.GaugeValue, stat.QueryEngine.QPS, c.serverStatValues(stat, role, stat.Server)...)
+
+	ch <- prometheus.MustNewConstMetric(c.serverQueriesTotal, prometheus.CounterValue, stat.QueryEngine.QueriesTotal, c.serverStatValues(stat, role, stat.Server)...)
+	ch <- prometheus.MustNewConstMetric(c.serverDocsTotal, prometheus.CounterValue, stat.QueryEngine.ReadDocsTotal, c.serverStatValues(stat, role, stat.Server, readOperation)...)
+	ch <- prometheus.MustNewConstMetric(c.serverDocsTotal, prometheus.CounterValue, stat.QueryEngine.WrittenDocsTotal, c.serverStatValues(stat, role, stat.Server, writtenOperation)...)
+}
+
+// dataServers queries the uuids of the data servers from the server_config system table if the role label is enabled.
+// The proxy servers are not configured there, they appear in the stats only.
+func (c *statsCollector) dataServers(ctx context.Context) (map[string]bool, error) {
+	if !c.e.opts.ServerRoleLabel {
+		return nil, nil
+	}
+	var configs []struct {
+		ID string `rethinkdb:"id"`
+	}
+	err := c.e.readAll(ctx, r.ServerConfigSystemTable, c.e.systemTable(r.ServerConfigSystemTable).Pluck("id"), &configs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query system server_config table: %w", err)
+	}
+	servers := make(map[string]bool, len(configs))
+	for _, config := range configs {
+		servers[config.ID] = true
+	}
+	return servers, nil
+}
+
+// serverRole returns the role of the server, either data or proxy
+func serverRole(dataServers map[string]bool, id string) string {
+	if dataServers[id] {
+		return dataRole
+	}
+	return proxyRole
+}
+
+func (c *statsCollector) processTableStat(stat stat, ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(c.tableDocsPerSecond, prometheus.GaugeValue, stat.QueryEngine.ReadDocsPerSec, c.tableValues(stat, stat.Database, stat.Table, readOperation)...)
+	ch <- prometheus.MustNewConstMetric(c.tableDocsPerSecond, prometheus.GaugeValue, stat.QueryEngine.WrittenDocsPerSec, c.tableValues(stat, stat.Database, stat.Table, writtenOperation)...)
+}
+
+// tableInfoQueries queries rows count estimates of the tables concurrently as their stats are read,
+// at most the configured number of them at once. Estimates of the tables
+// not answering within the table info timeout are skipped.
+type tableInfoQueries struct {
+	c     *statsCollector
+	ctx   context.Context
+	ch    chan<- prometheus.Metric
+	wg    errgroup.Group
+	slots chan struct{}
+}
+
+// newTableInfoQueries prepares the queries of the estimates, nothing is queried if they are not collected
+func (c *statsCollector) newTableInfoQueries(ctx context.Context, ch chan<- prometheus.Metric) *tableInfoQueries {
+	q := &tableInfoQueries{c: c, ctx: ctx, ch: ch}
+	if c.e.opts.TableInfoConcurrency > 0 {
+		q.slots = make(chan struct{}, c.e.opts.TableInfoConcurrency)
+	}
+	return q
+}
+
+// query starts the query of the estimate of the table without blocking the reading of the stats
+func (q *tableInfoQueries) query(table stat) {
+	c, ctx, ch := q.c, q.ctx, q.ch
+	if c.tableRowsCount == nil {
+		return
+	}
+	dbName := table.Database
+	tableName := table.Table
+	tableID := table.tableID()
+
+	queued := time.Now()
+	q.wg.Go(func() error {
+		if q.slots != nil {
+			select {
+			case q.slots <- struct{}{}:
+				defer func() { <-q.slots }()
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		c.e.self.tableInfoQueueDuration.Observe(time.Since(queued).Seconds())
+
+		queryCtx := ctx
+		if c.e.opts.TableInfoTimeout > 0 {
+			var cancel context.CancelFunc
+			queryCtx, cancel = context.WithTimeout(ctx, c.e.opts.TableInfoTimeout)
+			defer cancel()
+		}
+
+		var info info
+		err := c.e.readOne(queryCtx, "table_info", r.DB(dbName).Table(tableName).Info(), &info)
+		if err != nil && ctx.Err() == nil && errors.Is(queryCtx.Err(), context.DeadlineExceeded) {
+			// the estimate of a slow table is dropped without failing the scrape
+			c.e.log.Warn("table info timeout reached, estimate is skipped", "db", dbName, "table", tableName, "timeout", c.e.opts.TableInfoTimeout)
+			c.e.self.tableInfoSkipped.Inc()
+			return nil
+		}
+		if err != nil {
+			c.e.log.Warn("failed to get table info", "db", dbName, "table", tableName, "error", err)
+			return err
+		}
+
+		sum := 0.0
+		for i, e := range info.DocCountEstimates {
+			sum += float64(e)
+			if c.tableShardDocsEstimate != nil {
+				ch <- prometheus.MustNewConstMetric(c.tableShardDocsEstimate, prometheus.GaugeValue, e, c.e.withTableIDValue(tableID, dbName, tableName, strconv.Itoa(i))...)
+			}
+		}
+
+		ch <- prometheus.MustNewConstMetric(c.tableRowsCount, prometheus.GaugeValue, sum, c.e.withTableIDValue(tableID, dbName, tableName)...)
+		return nil
+	})
+}
+
+// wait waits for the started queries of the estimates
+func (q *tableInfoQueries) wait() error {
+	err := q.wg.Wait()
+	if err != nil {
+		return tableInfoErr{fmt.Errorf("failed to get table info: %w", err)}
+	}
+	return nil
+}
+
+func (c *statsCollector) processTableServerStat(stat stat, ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(c.tableReplicaDocsPerSecond, prometheus.GaugeValue, stat.QueryEngine.ReadDocsPerSec, c.replicaValues(stat, stat.Database, stat.Table, stat.Server, readOperation)...)
+	ch <- prometheus.MustNewConstMetric(c.tableReplicaDocsPerSecond, prometheus.GaugeValue, stat.QueryEngine.WrittenDocsPerSec, c.replicaValues(stat, stat.Database, stat.Table, stat.Server, writtenOperation)...)
+
+	ch <- prometheus.MustNewConstMetric(c.tableReplicaCacheBytes, prometheus.GaugeValue, stat.StorageEngine.Cache.InUseBytes, c.replicaValues(stat, stat.Database, stat.Table, stat.Server)...)
+
+	ch <- prometheus.MustNewConstMetric(c.tableReplicaIO, prometheus.GaugeValue, stat.StorageEngine.Disk.ReadBytesPerSec, c.replicaValues(stat, stat.Database, stat.Table, stat.Server, readOperation)...)
+	ch <- prometheus.MustNewConstMetric(c.tableReplicaIO, prometheus.GaugeValue, stat.StorageEngine.Disk.WrittenBytesPerSec, c.replicaValues(stat, stat.Database, stat.Table, stat.Server, writtenOperation)...)
+
+	ch <- prometheus.MustNewConstMetric(c.tableReplicaDataBytes, prometheus.GaugeValue, stat.StorageEngine.Disk.SpaceUsage.DataBytes, c.replicaValues(stat, stat.Database, stat.Table, stat.Server)...)
+	ch <- prometheus.MustNewConstMetric(c.tableReplicaMetaBytes, prometheus.GaugeValue, stat.StorageEngine.Disk.SpaceUsage.MetadataBytes, c.replicaValues(stat, stat.Database, stat.Table, stat.Server)...)
+	ch <- prometheus.MustNewConstMetric(c.tableReplicaGarbageBytes, prometheus.GaugeValue, stat.StorageEngine.Disk.SpaceUsage.GarbageBytes, c.replicaValues(stat, stat.Database, stat.Table, stat.Server)...)
+	ch <- prometheus.MustNewConstMetric(c.tableReplicaPreallocBytes, prometheus.GaugeValue, stat.StorageEngine.Disk.SpaceUsage.PreallocatedBytes, c.replicaValues(stat, stat.Database, stat.Table, stat.Server)...)
+
+	ch <- prometheus.MustNewConstMetric(c.tableReplicaDocsTotal, prometheus.CounterValue, stat.QueryEngine.ReadDocsTotal, c.replicaValues(stat, stat.Database, stat.Table, stat.Server, readOperation)...)
+	ch <- prometheus.MustNewConstMetric(c.tableReplicaDocsTotal, prometheus.CounterValue, stat.QueryEngine.WrittenDocsTotal, c.replicaValues(stat, stat.Database, stat.Table, stat.Server, writtenOperation)...)
+
+	ch <- prometheus.MustNewConstMetric(c.tableReplicaIOTotal, prometheus.CounterValue, stat.StorageEngine.Disk.ReadBytesTotal, c.replicaValues(stat, stat.Database, stat.Table, stat.Server, readOperation)...)
+	ch <- prometheus.MustNewConstMetric(c.tableReplicaIOTotal, prometheus.CounterValue, stat.StorageEngine.Disk.WrittenBytesTotal, c.replicaValues(stat, stat.Database, stat.Table, stat.Server, writtenOperation)...)
+}
@@ -0,0 +1,167 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// flavors of the statsd protocol
+const (
+	StatsDFlavor    = "statsd"
+	DogStatsDFlavor = "dogstatsd"
+)
+
+// statsdMaxPacketSize keeps the packets below the usual MTU, lines are never split between packets
+const statsdMaxPacketSize = 1432
+
+// statsdReplacer replaces the characters which have a meaning in the statsd lines
+var statsdReplacer = strings.NewReplacer(":", "_", "|", "_", "@", "_", "#", "_", ",", "_", " ", "_", "\n", "_")
+
+// StatsDOptions defines emitting of the metrics as StatsD or DogStatsD gauges
+type StatsDOptions struct {
+	// Address of the statsd server or the datadog agent given as host:port, the metrics are not emitted if it is empty
+	Address string
+	// Flavor is either StatsDFlavor appending the labels to the names, or DogStatsDFlavor sending them as tags.
+	// It is DogStatsDFlavor if empty.
+	Flavor string
+	// Prefix is prepended to the names of the emitted metrics
+	Prefix string
+	// Interval of emitting the metrics
+	Interval time.Duration
+}
+
+// statsdOutput emits every sample as gauge over udp.
+// Counters are emitted with their cumulative value, histograms and summaries as their count, sum and quantiles.
+type statsdOutput struct {
+	opts StatsDOptions
+}
+
+func newStatsDOutput(opts StatsDOptions) (*statsdOutput, error) {
+	_, _, err := net.SplitHostPort(opts.Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid statsd address: %w", err)
+	}
+	switch opts.Flavor {
+	case "":
+		opts.Flavor = DogStatsDFlavor
+	case StatsDFlavor, DogStatsDFlavor:
+	default:
+		return nil, fmt.Errorf("unknown statsd flavor %q, expected %q or %q", opts.Flavor, StatsDFlavor, DogStatsDFlavor)
+	}
+	if opts.Interval <= 0 {
+		return nil, fmt.Errorf("statsd push interval must be positive, got %s", opts.Interval)
+	}
+	return &statsdOutput{opts: opts}, nil
+}
+
+func (o *statsdOutput) push(ctx context.Context, families []*dto.MetricFamily) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", o.opts.Address)
+	if err != nil {
+		return fmt.Errorf("failed to connect to statsd: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	var packet bytes.Buffer
+	flush := func() error {
+		if packet.Len() == 0 {
+			return nil
+		}
+		_, err := conn.Write(packet.Bytes())
+		packet.Reset()
+		if err != nil {
+			return fmt.Errorf("failed to send to statsd: %w", err)
+		}
+		return nil
+	}
+	for _, line := range o.lines(families) {
+		if packet.Len() > 0 && packet.Len()+1+len(line) > statsdMaxPacketSize {
+			err = flush()
+			if err != nil {
+				return err
+			}
+		}
+		if packet.Len() > 0 {
+			packet.WriteByte('\n')
+		}
+		packet.WriteString(line)
+	}
+	return flush()
+}
+
+// lines converts the samples of the metric families to the statsd gauge lines,
+// samples which are not finite are dropped
+func (o *statsdOutput) lines(families []*dto.MetricFamily) []string {
+	var lines []string
+	add := func(name string, labels []*dto.LabelPair, value float64) {
+		if finite(value) {
+			lines = append(lines, o.line(name, labels, value))
+		}
+	}
+	quantileLabel := "quantile"
+	for _, f := range families {
+		name := f.GetName()
+		for _, m := range f.GetMetric() {
+			labels := m.GetLabel()
+			switch f.GetType() {
+			case dto.MetricType_COUNTER:
+				add(name, labels, m.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				add(name, labels, m.GetGauge().GetValue())
+			case dto.MetricType_UNTYPED:
+				add(name, labels, m.GetUntyped().GetValue())
+			case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+				add(name+"_count", labels, float64(m.GetHistogram().GetSampleCount()))
+				add(name+"_sum", labels, m.GetHistogram().GetSampleSum())
+			case dto.MetricType_SUMMARY:
+				add(name+"_count", labels, float64(m.GetSummary().GetSampleCount()))
+				add(name+"_sum", labels, m.GetSummary().GetSampleSum())
+				for _, q := range m.GetSummary().GetQuantile() {
+					quantile := strconv.FormatFloat(q.GetQuantile(), 'g', -1, 64)
+					add(name, append(slices.Clip(labels), &dto.LabelPair{Name: &quantileLabel, Value: &quantile}), q.GetValue())
+				}
+			}
+		}
+	}
+	return lines
+}
+
+// line formats the gauge line of the sample, the labels are either tags or parts of the name
+func (o *statsdOutput) line(name string, labels []*dto.LabelPair, value float64) string {
+	var b strings.Builder
+	b.WriteString(statsdReplacer.Replace(o.opts.Prefix + name))
+	if o.opts.Flavor == StatsDFlavor {
+		for _, l := range labels {
+			b.WriteString(".")
+			b.WriteString(statsdReplacer.Replace(l.GetName() + "_" + strings.ReplaceAll(l.GetValue(), ".", "_")))
+		}
+	}
+	b.WriteString(":")
+	b.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	b.WriteString("|g")
+	if o.opts.Flavor == DogStatsDFlavor && len(labels) > 0 {
+		b.WriteString("|#")
+		for i, l := range labels {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			b.WriteString(statsdReplacer.Replace(l.GetName()))
+			b.WriteString(":")
+			b.WriteString(strings.NewReplacer("|", "_", ",", "_", "\n", "_").Replace(l.GetValue()))
+		}
+	}
+	return b.String()
+}
+
+func finite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
@@ -0,0 +1,185 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	r "gopkg.in/rethinkdb/rethinkdb-go.v6"
+	"gopkg.in/rethinkdb/rethinkdb-go.v6/encoding"
+)
+
+// streamRetryInterval is the wait before a failed changefeed is opened again
+const streamRetryInterval = 5 * time.Second
+
+// streamedTables are the system tables kept up to date by changefeeds in the streaming mode
+var streamedTables = []string{r.StatsSystemTable, r.TableStatusSystemTable}
+
+// systemTableStream keeps documents of a system table updated by its changefeed
+type systemTableStream struct {
+	name string
+
+	m     sync.RWMutex
+	ready bool
+	docs  map[string]interface{}
+}
+
+// streams are the changefeeds of the exporter, they are opened on the first scrape
+type streams struct {
+	m       sync.Mutex
+	started bool
+	stopped bool
+	cancel  context.CancelFunc
+	tables  map[string]*systemTableStream
+}
+
+// startStreams opens the changefeeds of the streamed tables once if the streaming mode is enabled
+func (e *RethinkdbExporter) startStreams() {
+	if !e.opts.StreamStats {
+		return
+	}
+
+	e.streams.m.Lock()
+	defer e.streams.m.Unlock()
+	if e.streams.started || e.streams.stopped {
+		return
+	}
+	e.streams.started = true
+
+	var ctx context.Context
+	ctx, e.streams.cancel = context.WithCancel(context.Background())
+	e.streams.tables = make(map[string]*systemTableStream, len(streamedTables))
+	for _, name := range streamedTables {
+		s := &systemTableStream{name: name}
+		e.streams.tables[name] = s
+		go e.runStream(ctx, s)
+	}
+}
+
+// stopStreams closes the changefeeds of the exporter and of the other clusters
+func (e *RethinkdbExporter) stopStreams() {
+	e.streams.m.Lock()
+	e.streams.stopped = true
+	if e.streams.cancel != nil {
+		e.streams.cancel()
+	}
+	e.streams.m.Unlock()
+
+	for _, c := range e.clusters {
+		c.stopStreams()
+	}
+}
+
+// streamed returns the current documents of the system table if its changefeed is ready
+func (e *RethinkdbExporter) streamed(name string) ([]interface{}, bool) {
+	e.streams.m.Lock()
+	s, ok := e.streams.tables[name]
+	e.streams.m.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	s.m.RLock()
+	defer s.m.RUnlock()
+	if !s.ready {
+		return nil, false
+	}
+	docs := make([]interface{}, 0, len(s.docs))
+	for _, doc := range s.docs {
+		docs = append(docs, doc)
+	}
+	return docs, true
+}
+
+// readStreamed decodes the current documents of the system table into the result slice
+// if its changefeed is ready, otherwise the system table is queried
+func (e *RethinkdbExporter) readStreamed(ctx context.Context, name string, term r.Term, result interface{}) error {
+	docs, ok := e.streamed(name)
+	if !ok {
+		return e.readAll(ctx, name, term, result)
+	}
+	err := encoding.Decode(result, docs)
+	if err != nil {
+		return fmt.Errorf("failed to decode streamed %s documents: %w", name, err)
+	}
+	return nil
+}
+
+// runStream keeps the documents of the stream up to date until the context is done,
+// the changefeed is opened again after it fails
+func (e *RethinkdbExporter) runStream(ctx context.Context, s *systemTableStream) {
+	for {
+		err := e.followChanges(ctx, s)
+		s.reset()
+		if ctx.Err() != nil {
+			return
+		}
+		e.log.Error("changefeed failed, the table is queried until it is opened again", "table", s.name, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(streamRetryInterval):
+		}
+	}
+}
+
+// followChanges applies the changes of the system table to the stream, starting with its initial documents
+func (e *RethinkdbExporter) followChanges(ctx context.Context, s *systemTableStream) error {
+	cur, err := e.systemTable(s.name).
+		Changes(r.ChangesOpts{IncludeInitial: true, IncludeStates: true}).
+		Run(e.rconn, r.RunOpts{Context: ctx})
+	if err != nil {
+		return fmt.Errorf("failed to open changefeed: %w", err)
+	}
+	defer func() { _ = cur.Close() }()
+
+	var change r.ChangeResponse
+	for cur.Next(&change) {
+		s.apply(change)
+		change = r.ChangeResponse{}
+	}
+	if cur.Err() != nil {
+		return fmt.Errorf("changefeed error: %w", cur.Err())
+	}
+	return errors.New("changefeed closed")
+}
+
+// apply updates the documents with the change, the stream is ready after the initial documents are received
+func (s *systemTableStream) apply(change r.ChangeResponse) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if s.docs == nil {
+		s.docs = make(map[string]interface{})
+	}
+	switch {
+	case change.State == "ready":
+		s.ready = true
+	case change.State != "":
+		// the initializing state is not tracked
+	case change.NewValue != nil:
+		s.docs[documentID(change.NewValue)] = change.NewValue
+	case change.OldValue != nil:
+		delete(s.docs, documentID(change.OldValue))
+	}
+}
+
+// reset drops the documents after the changefeed failed
+func (s *systemTableStream) reset() {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.ready = false
+	s.docs = nil
+}
+
+// documentID returns the key of the document by its id, which is an array in the stats table
+func documentID(doc interface{}) string {
+	m, ok := doc.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%q", m["id"])
+}
@@ -0,0 +1,102 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	r "gopkg.in/rethinkdb/rethinkdb-go.v6"
+)
+
+func init() {
+	registerCollector("table_config", true, newTableConfigCollector)
+}
+
+// tableConfigCollector collects configuration of the tables from the rethinkdb.table_config system table
+type tableConfigCollector struct {
+	e *RethinkdbExporter
+
+	info                    *prometheus.Desc
+	shards                  *prometheus.Desc
+	shardConfiguredReplicas *prometheus.Desc
+	configuredReplicas      *prometheus.Desc
+}
+
+func newTableConfigCollector(e *RethinkdbExporter) collector {
+	return &tableConfigCollector{
+		e: e,
+		info: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "table", "config_info"),
+			"Durability and write acknowledgements settings of the table, always 1",
+			e.withTableID("db", "table", "durability", "write_acks")...),
+		shards: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "table", "shards"),
+			"Number of configured shards of the table",
+			e.withTableID("db", "table")...),
+		shardConfiguredReplicas: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "table", "shard_configured_replicas"),
+			"Number of configured replicas of the table shard",
+			e.withTableID("db", "table", "shard")...),
+		configuredReplicas: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "table", "configured_replicas"),
+			"Number of configured replicas of all shards of the table by whether they vote",
+			e.withTableID("db", "table", "voting")...),
+	}
+}
+
+type tableConfig struct {
+	ID         string `rethinkdb:"id"`
+	Name       string `rethinkdb:"name"`
+	Database   string `rethinkdb:"db"`
+	Durability string `rethinkdb:"durability"`
+	// WriteAcks is either "majority", "single" or a list of custom requirements
+	WriteAcks interface{}        `rethinkdb:"write_acks"`
+	Shards    []tableConfigShard `rethinkdb:"shards"`
+}
+
+type tableConfigShard struct {
+	PrimaryReplica string `rethinkdb:"primary_replica"`
+	// Replicas include the non-voting ones
+	Replicas          []string `rethinkdb:"replicas"`
+	NonvotingReplicas []string `rethinkdb:"nonvoting_replicas"`
+}
+
+// Describe sends metrics descriptions to the prometheus chan
+func (c *tableConfigCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.info
+	ch <- c.shards
+	ch <- c.shardConfiguredReplicas
+	ch <- c.configuredReplicas
+}
+
+// Update sends collected metrics values to the prometheus chan
+func (c *tableConfigCollector) Update(ctx context.Context, ch chan<- prometheus.Metric) error {
+	var configs []tableConfig
+	err := c.e.readAll(ctx, r.TableConfigSystemTable, c.e.systemTable(r.TableConfigSystemTable), &configs)
+	if err != nil {
+		return fmt.Errorf("failed to query system table_config table: %w", err)
+	}
+
+	for _, config := range configs {
+		if !c.e.filters.tableMatches(config.Database, config.Name) {
+			continue
+		}
+		writeAcks, ok := config.WriteAcks.(string)
+		if !ok {
+			writeAcks = "custom"
+		}
+		ch <- prometheus.MustNewConstMetric(c.info, prometheus.GaugeValue, 1, c.e.withTableIDValue(config.ID, config.Database, config.Name, config.Durability, writeAcks)...)
+
+		ch <- prometheus.MustNewConstMetric(c.shards, prometheus.GaugeValue, float64(len(config.Shards)), c.e.withTableIDValue(config.ID, config.Database, config.Name)...)
+		voting, nonvoting := 0, 0
+		for i, shard := range config.Shards {
+			ch <- prometheus.MustNewConstMetric(c.shardConfiguredReplicas, prometheus.GaugeValue, float64(len(shard.Replicas)), c.e.withTableIDValue(config.ID, config.Database, config.Name, strconv.Itoa(i))...)
+			voting += len(shard.Replicas) - len(shard.NonvotingReplicas)
+			nonvoting += len(shard.NonvotingReplicas)
+		}
+		ch <- prometheus.MustNewConstMetric(c.configuredReplicas, prometheus.GaugeValue, float64(voting), c.e.withTableIDValue(config.ID, config.Database, config.Name, "true")...)
+		ch <- prometheus.MustNewConstMetric(c.configuredReplicas, prometheus.GaugeValue, float64(nonvoting), c.e.withTableIDValue(config.ID, config.Database, config.Name, "false")...)
+	}
+	return nil
+}
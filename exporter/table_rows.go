@@ -0,0 +1,129 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/errgroup"
+	r "gopkg.in/rethinkdb/rethinkdb-go.v6"
+)
+
+// defaultExactCountInterval is the interval of counting rows of the tables if it is not configured
+const defaultExactCountInterval = time.Hour
+
+func init() {
+	registerCollector("table_rows_exact", false, newTableRowsCollector)
+}
+
+// tableRowsCollector counts rows of the selected tables exactly.
+// Counting reads the whole tables, so it is run in the background on the long interval
+// and the scrapes are served the last counts.
+type tableRowsCollector struct {
+	e *RethinkdbExporter
+
+	rows *prometheus.Desc
+
+	m        sync.Mutex
+	counting bool
+	counted  time.Time
+	counts   map[tableKey]float64
+	err      error
+}
+
+type tableKey struct {
+	db, table string
+}
+
+func newTableRowsCollector(e *RethinkdbExporter) collector {
+	return &tableRowsCollector{
+		e: e,
+		rows: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "table", "rows_exact"),
+			"Exact number of rows in the table counted on the exact count interval",
+			"db", "table"),
+		counts: make(map[tableKey]float64),
+	}
+}
+
+// validateExactCountTables checks that the tables are given as db.table
+func validateExactCountTables(tables []string) error {
+	for _, t := range tables {
+		db, table, ok := strings.Cut(t, ".")
+		if !ok || db == "" || table == "" {
+			return fmt.Errorf("exact count table %q is not given as db.table", t)
+		}
+	}
+	return nil
+}
+
+// Describe sends metrics descriptions to the prometheus chan
+func (c *tableRowsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.rows
+}
+
+// Update starts counting in the background if the interval elapsed
+// and sends the last counts to the prometheus chan
+func (c *tableRowsCollector) Update(_ context.Context, ch chan<- prometheus.Metric) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	interval := c.e.opts.ExactCountInterval
+	if interval <= 0 {
+		interval = defaultExactCountInterval
+	}
+	if !c.counting && time.Since(c.counted) >= interval {
+		c.counting = true
+		// counting is not bound to the scrape, it is limited by the interval instead
+		go c.count(interval)
+	}
+
+	for key, count := range c.counts {
+		ch <- prometheus.MustNewConstMetric(c.rows, prometheus.GaugeValue, count, key.db, key.table)
+	}
+	return c.err
+}
+
+// count counts rows of the tables concurrently and replaces the last counts
+func (c *tableRowsCollector) count(timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var (
+		m      sync.Mutex
+		counts = make(map[tableKey]float64)
+		errs   []error
+	)
+	wg := &errgroup.Group{}
+	if c.e.opts.TableInfoConcurrency > 0 {
+		wg.SetLimit(c.e.opts.TableInfoConcurrency)
+	}
+	for _, t := range c.e.opts.ExactCountTables {
+		dbName, tableName, _ := strings.Cut(t, ".")
+		wg.Go(func() error {
+			var count float64
+			err := c.e.readOne(ctx, "table_count", r.DB(dbName).Table(tableName).Count(), &count)
+			m.Lock()
+			defer m.Unlock()
+			if err != nil {
+				c.e.log.Warn("failed to count table rows", "db", dbName, "table", tableName, "error", err)
+				errs = append(errs, fmt.Errorf("failed to count rows of %s.%s: %w", dbName, tableName, err))
+				return nil
+			}
+			counts[tableKey{db: dbName, table: tableName}] = count
+			return nil
+		})
+	}
+	_ = wg.Wait()
+
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.counting = false
+	c.counted = time.Now()
+	c.counts = counts
+	c.err = errors.Join(errs...)
+}
@@ -0,0 +1,171 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	r "gopkg.in/rethinkdb/rethinkdb-go.v6"
+)
+
+func init() {
+	registerCollector("table_status", true, newTableStatusCollector)
+}
+
+// replicaStates are the states of the table replicas in the table_status system table,
+// the replica state metric is exported for all of them
+var replicaStates = []string{"ready", "transitioning", "backfilling", "disconnected", "waiting_for_primary", "waiting_for_quorum"}
+
+// tableStatusCollector collects availability of the tables from the rethinkdb.table_status system table
+type tableStatusCollector struct {
+	e *RethinkdbExporter
+
+	readyForOutdatedReads *prometheus.Desc
+	readyForReads         *prometheus.Desc
+	readyForWrites        *prometheus.Desc
+	allReplicasReady      *prometheus.Desc
+
+	shardReplicas      *prometheus.Desc
+	shardReadyReplicas *prometheus.Desc
+	replicaState       *prometheus.Desc
+
+	serverPrimaryShards *prometheus.Desc
+}
+
+func newTableStatusCollector(e *RethinkdbExporter) collector {
+	return &tableStatusCollector{
+		e: e,
+		readyForOutdatedReads: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "table", "ready_for_outdated_reads"),
+			"Whether the table is ready for reads with outdated read mode",
+			e.withTableID("db", "table")...),
+		readyForReads: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "table", "ready_for_reads"),
+			"Whether the table is ready for reads",
+			e.withTableID("db", "table")...),
+		readyForWrites: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "table", "ready_for_writes"),
+			"Whether the table is ready for writes",
+			e.withTableID("db", "table")...),
+		allReplicasReady: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "table", "all_replicas_ready"),
+			"Whether all replicas of the table are ready",
+			e.withTableID("db", "table")...),
+		shardReplicas: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "table", "shard_replicas"),
+			"Number of replicas of the table shard",
+			e.withTableID("db", "table", "shard")...),
+		shardReadyReplicas: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "table", "shard_replicas_ready"),
+			"Number of ready replicas of the table shard",
+			e.withTableID("db", "table", "shard")...),
+		replicaState: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "table", "replica_state"),
+			"Whether the replica of the table shard on the server is in the state",
+			e.withTableID("db", "table", "shard", "server", "state")...),
+		serverPrimaryShards: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "server", "primary_shards"),
+			"Number of table shards whose primary replica is the server",
+			"server"),
+	}
+}
+
+type tableStatus struct {
+	ID       string `rethinkdb:"id"`
+	Name     string `rethinkdb:"name"`
+	Database string `rethinkdb:"db"`
+	Status   struct {
+		AllReplicasReady      bool `rethinkdb:"all_replicas_ready"`
+		ReadyForOutdatedReads bool `rethinkdb:"ready_for_outdated_reads"`
+		ReadyForReads         bool `rethinkdb:"ready_for_reads"`
+		ReadyForWrites        bool `rethinkdb:"ready_for_writes"`
+	} `rethinkdb:"status"`
+	Shards []tableStatusShard `rethinkdb:"shards"`
+}
+
+type tableStatusShard struct {
+	PrimaryReplicas []string `rethinkdb:"primary_replicas"`
+	Replicas        []struct {
+		Server string `rethinkdb:"server"`
+		State  string `rethinkdb:"state"`
+	} `rethinkdb:"replicas"`
+}
+
+// Describe sends metrics descriptions to the prometheus chan
+func (c *tableStatusCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.readyForOutdatedReads
+	ch <- c.readyForReads
+	ch <- c.readyForWrites
+	ch <- c.allReplicasReady
+
+	ch <- c.shardReplicas
+	ch <- c.shardReadyReplicas
+	ch <- c.replicaState
+
+	ch <- c.serverPrimaryShards
+}
+
+// Update sends collected metrics values to the prometheus chan
+func (c *tableStatusCollector) Update(ctx context.Context, ch chan<- prometheus.Metric) error {
+	var statuses []tableStatus
+	err := c.e.readStreamed(ctx, r.TableStatusSystemTable, c.e.systemTable(r.TableStatusSystemTable), &statuses)
+	if err != nil {
+		return fmt.Errorf("failed to query system table_status table: %w", err)
+	}
+
+	// the servers replicating a shard without being its primary are counted with 0 primaries
+	primaries := make(map[string]int)
+	for _, status := range statuses {
+		if !c.e.filters.tableMatches(status.Database, status.Name) {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.readyForOutdatedReads, prometheus.GaugeValue, boolToFloat(status.Status.ReadyForOutdatedReads), c.e.withTableIDValue(status.ID, status.Database, status.Name)...)
+		ch <- prometheus.MustNewConstMetric(c.readyForReads, prometheus.GaugeValue, boolToFloat(status.Status.ReadyForReads), c.e.withTableIDValue(status.ID, status.Database, status.Name)...)
+		ch <- prometheus.MustNewConstMetric(c.readyForWrites, prometheus.GaugeValue, boolToFloat(status.Status.ReadyForWrites), c.e.withTableIDValue(status.ID, status.Database, status.Name)...)
+		ch <- prometheus.MustNewConstMetric(c.allReplicasReady, prometheus.GaugeValue, boolToFloat(status.Status.AllReplicasReady), c.e.withTableIDValue(status.ID, status.Database, status.Name)...)
+
+		for i, shard := range status.Shards {
+			for _, server := range shard.PrimaryReplicas {
+				primaries[server]++
+			}
+			ready := 0
+			for _, replica := range shard.Replicas {
+				if _, ok := primaries[replica.Server]; !ok {
+					primaries[replica.Server] = 0
+				}
+				if replica.State == "ready" {
+					ready++
+				}
+			}
+
+			shardID := strconv.Itoa(i)
+			ch <- prometheus.MustNewConstMetric(c.shardReplicas, prometheus.GaugeValue, float64(len(shard.Replicas)), c.e.withTableIDValue(status.ID, status.Database, status.Name, shardID)...)
+			ch <- prometheus.MustNewConstMetric(c.shardReadyReplicas, prometheus.GaugeValue, float64(ready), c.e.withTableIDValue(status.ID, status.Database, status.Name, shardID)...)
+			for _, replica := range shard.Replicas {
+				c.processReplicaState(status, shardID, replica.Server, replica.State, ch)
+			}
+		}
+	}
+
+	for server, count := range primaries {
+		if !c.e.filters.serverMatches(server) {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.serverPrimaryShards, prometheus.GaugeValue, float64(count), server)
+	}
+	return nil
+}
+
+// processReplicaState sends the replica state metric for every known state,
+// a state unknown to the exporter is sent as well so it isn't lost
+func (c *tableStatusCollector) processReplicaState(status tableStatus, shardID, server, state string, ch chan<- prometheus.Metric) {
+	known := false
+	for _, s := range replicaStates {
+		known = known || s == state
+		ch <- prometheus.MustNewConstMetric(c.replicaState, prometheus.GaugeValue, boolToFloat(s == state), c.e.withTableIDValue(status.ID, status.Database, status.Name, shardID, server, s)...)
+	}
+	if !known {
+		ch <- prometheus.MustNewConstMetric(c.replicaState, prometheus.GaugeValue, 1, c.e.withTableIDValue(status.ID, status.Database, status.Name, shardID, server, state)...)
+	}
+}
@@ -0,0 +1,74 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// textfileName is the name of the file written to the textfile directory
+const textfileName = "rethinkdb_exporter.prom"
+
+// textfileSkippedPrefixes are prefixes of the runtime metrics of the exporter process,
+// which collide with the same metrics of the node_exporter reading the file
+var textfileSkippedPrefixes = []string{"go_", "process_", "promhttp_"}
+
+// TextfileOptions defines writing of the metrics for the textfile collector of the node_exporter
+type TextfileOptions struct {
+	// Dir is the directory read by the textfile collector, the metrics are not written if it is empty.
+	// The http-server is not started if it is set.
+	Dir string
+	// Interval of writing the metrics
+	Interval time.Duration
+}
+
+// textfileOutput replaces the file in the textfile directory atomically with every write
+type textfileOutput struct {
+	path string
+}
+
+func newTextfileOutput(opts TextfileOptions) (*textfileOutput, error) {
+	info, err := os.Stat(opts.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid textfile directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("textfile directory %s is not a directory", opts.Dir)
+	}
+	if opts.Interval <= 0 {
+		return nil, fmt.Errorf("textfile write interval must be positive, got %s", opts.Interval)
+	}
+	return &textfileOutput{path: filepath.Join(opts.Dir, textfileName)}, nil
+}
+
+func (o *textfileOutput) push(_ context.Context, families []*dto.MetricFamily) error {
+	err := prometheus.WriteToTextfile(o.path, prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		written := make([]*dto.MetricFamily, 0, len(families))
+		for _, f := range families {
+			if !hasAnyPrefix(f.GetName(), textfileSkippedPrefixes) {
+				written = append(written, f)
+			}
+		}
+		return written, nil
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to write textfile: %w", err)
+	}
+	return nil
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
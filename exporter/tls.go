@@ -0,0 +1,43 @@
+package exporter
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// PrepareServerTLSConfig creates tls.Config of the http-server with certificate files.
+// Client certificates signed by the CA are required if the client CA file is given.
+func PrepareServerTLSConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	if len(certFile) == 0 || len(keyFile) == 0 {
+		return nil, errors.New("cert file and key file must be both specified")
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("TLS file load error: %w", err)
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if len(clientCAFile) != 0 {
+		ca, err := os.ReadFile(clientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("TLS client CA file load error: %w", err)
+		}
+
+		cp := x509.NewCertPool()
+		if !cp.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("TLS credentials: failed to append client ca")
+		}
+
+		config.ClientCAs = cp
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return config, nil
+}
@@ -0,0 +1,101 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	r "gopkg.in/rethinkdb/rethinkdb-go.v6"
+)
+
+func init() {
+	registerCollector("users", false, newUsersCollector)
+}
+
+// permissionKinds are the kinds of permissions of the users in the order they are exported
+var permissionKinds = []string{"read", "write", "config", "connect"}
+
+// usersCollector collects the users and their global permissions from the rethinkdb.users
+// and rethinkdb.permissions system tables, which are readable by the admin only
+type usersCollector struct {
+	e *RethinkdbExporter
+
+	users                 *prometheus.Desc
+	usersWithoutPassword  *prometheus.Desc
+	usersGlobalPermission *prometheus.Desc
+}
+
+func newUsersCollector(e *RethinkdbExporter) collector {
+	return &usersCollector{
+		e: e,
+		users: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "cluster", "users"),
+			"Number of users of the cluster"),
+		usersWithoutPassword: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "cluster", "users_without_password"),
+			"Number of users of the cluster without a password"),
+		usersGlobalPermission: e.newDesc(
+			prometheus.BuildFQName(e.namespace, "cluster", "users_with_global_permission"),
+			"Number of users granted the permission globally by kind, the admin user has all of them",
+			"permission"),
+	}
+}
+
+type user struct {
+	ID       string `rethinkdb:"id"`
+	Password bool   `rethinkdb:"password"`
+}
+
+type permission struct {
+	// ID is the user followed by the uuids of the database and the table the permissions are granted on,
+	// only the user for the global permissions
+	ID          []string        `rethinkdb:"id"`
+	Permissions map[string]bool `rethinkdb:"permissions"`
+}
+
+// Describe sends metrics descriptions to the prometheus chan
+func (c *usersCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.users
+	ch <- c.usersWithoutPassword
+	ch <- c.usersGlobalPermission
+}
+
+// Update sends collected metrics values to the prometheus chan
+func (c *usersCollector) Update(ctx context.Context, ch chan<- prometheus.Metric) error {
+	var users []user
+	err := c.e.readAll(ctx, r.UsersSystemTable, c.e.systemTable(r.UsersSystemTable), &users)
+	if err != nil {
+		return fmt.Errorf("failed to query system users table: %w", err)
+	}
+	var permissions []permission
+	err = c.e.readAll(ctx, r.PermissionsSystemTable, c.e.systemTable(r.PermissionsSystemTable), &permissions)
+	if err != nil {
+		return fmt.Errorf("failed to query system permissions table: %w", err)
+	}
+
+	withoutPassword := 0
+	for _, u := range users {
+		if !u.Password {
+			withoutPassword++
+		}
+	}
+
+	global := make(map[string]int, len(permissionKinds))
+	for _, p := range permissions {
+		if len(p.ID) != 1 {
+			continue
+		}
+		for _, kind := range permissionKinds {
+			if p.Permissions[kind] {
+				global[kind]++
+			}
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.users, prometheus.GaugeValue, float64(len(users)))
+	ch <- prometheus.MustNewConstMetric(c.usersWithoutPassword, prometheus.GaugeValue, float64(withoutPassword))
+	for _, kind := range permissionKinds {
+		ch <- prometheus.MustNewConstMetric(c.usersGlobalPermission, prometheus.GaugeValue, float64(global[kind]), kind)
+	}
+	return nil
+}
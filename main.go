@@ -9,6 +9,6 @@ import (
 func main() {
 	err := cmd.Execute()
 	if err != nil {
-		log.Fatalf("error starting rethinkdb exporter: %v", err)
+		log.Fatalf("rethinkdb exporter failed: %v", err)
 	}
 }